@@ -0,0 +1,258 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Algorithm is a sequence of moves, performed left to right, that can be
+// combined with other algorithms.
+type Algorithm []Move
+
+// Inverse returns the algorithm that undoes a
+func (a Algorithm) Inverse() Algorithm {
+	ret := make(Algorithm, len(a))
+	for i, m := range a {
+		m.Direction = !m.Direction
+		ret[len(a)-1-i] = m
+	}
+	return ret
+}
+
+// Repeat performs a k times in a row.  A negative k repeats the inverse.
+func (a Algorithm) Repeat(k int) Algorithm {
+	if k < 0 {
+		return a.Inverse().Repeat(-k)
+	}
+	ret := make(Algorithm, 0, len(a)*k)
+	for range k {
+		ret = append(ret, a...)
+	}
+	return ret
+}
+
+// Concat performs a followed by the other algorithms
+func (a Algorithm) Concat(others ...Algorithm) Algorithm {
+	ret := append(Algorithm{}, a...)
+	for _, other := range others {
+		ret = append(ret, other...)
+	}
+	return ret
+}
+
+// Conjugate returns [setup: a], that is, setup followed by a followed by
+// the inverse of setup
+func (a Algorithm) Conjugate(setup Algorithm) Algorithm {
+	return setup.Concat(a, setup.Inverse())
+}
+
+// Commutator returns [a, b], that is, a b a' b'
+func Commutator(a, b Algorithm) Algorithm {
+	return a.Concat(b, a.Inverse(), b.Inverse())
+}
+
+// Simplify cancels moves that undo each other.
+//
+// Moves about the same axis commute, so a run of them is replaced by at
+// most one quarter turn, or two quarter turns for a half turn, per layer.
+// Runs that cancel out entirely disappear, which may in turn let their
+// neighbours cancel: R U U' R' simplifies to nothing.
+func (a Algorithm) Simplify() Algorithm {
+	type run struct {
+		axis   Axis
+		amount map[int]int // quarter turns, clockwise, per layer index
+	}
+	var runs []run
+	for _, m := range a {
+		if len(runs) == 0 || runs[len(runs)-1].axis != m.Axis {
+			runs = append(runs, run{axis: m.Axis, amount: make(map[int]int)})
+		}
+		last := runs[len(runs)-1]
+		if m.Direction == Clock {
+			last.amount[m.Idx] = (last.amount[m.Idx] + 1) % 4
+		} else {
+			last.amount[m.Idx] = (last.amount[m.Idx] + 3) % 4
+		}
+		if last.amount[m.Idx] == 0 {
+			delete(last.amount, m.Idx)
+		}
+		if len(last.amount) == 0 {
+			runs = runs[:len(runs)-1]
+		}
+	}
+
+	ret := Algorithm{}
+	for _, r := range runs {
+		var idxs []int
+		for idx := range r.amount {
+			idxs = append(idxs, idx)
+		}
+		sort.Ints(idxs)
+		for _, idx := range idxs {
+			switch r.amount[idx] {
+			case 1:
+				ret = append(ret, Move{Axis: r.axis, Idx: idx, Direction: Clock})
+			case 2:
+				ret = append(ret, Move{Axis: r.axis, Idx: idx, Direction: Clock}, Move{Axis: r.axis, Idx: idx, Direction: Clock})
+			case 3:
+				ret = append(ret, Move{Axis: r.axis, Idx: idx, Direction: Counterclock})
+			}
+		}
+	}
+	return ret
+}
+
+// Format writes the algorithm in Singmaster notation for a cube of size n
+func (a Algorithm) Format(n uint) string {
+	return FormatMoves(n, a)
+}
+
+// ParseAlgorithm reads an algorithm for a cube of size n.  On top of the
+// moves understood by ParseMoves, it accepts
+//
+//	(A)    grouping
+//	(A)k   A repeated k times
+//	(A)'   the inverse of A
+//	[A, B] the commutator A B A' B'
+//	[A: B] the conjugate A B A'
+//
+// Brackets nest, and repetitions and inverses also apply to commutators
+// and conjugates, as in [R, U]3.
+func ParseAlgorithm(n uint, s string) (Algorithm, error) {
+	p := algorithmParser{n: n, s: s}
+	a, err := p.sequence(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(s) {
+		return nil, &NotationError{Pos: p.pos, Token: s[p.pos : p.pos+1], Msg: "unexpected"}
+	}
+	return a, nil
+}
+
+type algorithmParser struct {
+	n   uint
+	s   string
+	pos int
+}
+
+func (p *algorithmParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// Parses moves and groups up to a closing bracket, a separator or the end.
+// outer is the number of moves already parsed around the sequence.
+func (p *algorithmParser) sequence(outer int) (Algorithm, error) {
+	ret := Algorithm{}
+	for {
+		p.skipSpaces()
+		if p.pos == len(p.s) {
+			return ret, nil
+		}
+		switch p.s[p.pos] {
+		case ')', ']', ',', ':':
+			return ret, nil
+		case '(', '[':
+			a, err := p.group(outer + len(ret))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, a...)
+		default:
+			ms, next, err := parseMove(p.n, p.s, p.pos)
+			if err != nil {
+				return nil, err
+			}
+			if outer+len(ret)+len(ms) > maxAlgorithmLength {
+				return nil, &NotationError{Pos: p.pos, Token: p.s[p.pos:next], Msg: "algorithm too long from"}
+			}
+			ret = append(ret, ms...)
+			p.pos = next
+		}
+	}
+}
+
+// The most moves an algorithm may expand to, so that large counts or
+// deeply nested commutators cannot exhaust memory
+const maxAlgorithmLength = 1 << 20
+
+// Parses a bracketed group and its suffixes.  outer is the number of
+// moves already parsed around the group.
+func (p *algorithmParser) group(outer int) (Algorithm, error) {
+	start := p.pos
+	open := p.s[p.pos]
+	p.pos++
+	first, err := p.sequence(outer)
+	if err != nil {
+		return nil, err
+	}
+	unclosed := &NotationError{Pos: start, Token: string(open), Msg: "unclosed bracket"}
+	if p.pos == len(p.s) {
+		return nil, unclosed
+	}
+
+	var ret Algorithm
+	if open == '(' {
+		if p.s[p.pos] != ')' {
+			return nil, &NotationError{Pos: p.pos, Token: p.s[p.pos : p.pos+1], Msg: "expected )"}
+		}
+		ret = first
+	} else {
+		sep := p.s[p.pos]
+		if sep != ',' && sep != ':' {
+			return nil, &NotationError{Pos: p.pos, Token: p.s[p.pos : p.pos+1], Msg: "expected , or : in"}
+		}
+		p.pos++
+		second, err := p.sequence(outer + len(first))
+		if err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.s) {
+			return nil, unclosed
+		}
+		if p.s[p.pos] != ']' {
+			return nil, &NotationError{Pos: p.pos, Token: p.s[p.pos : p.pos+1], Msg: "expected ]"}
+		}
+		length := 2*len(first) + len(second)
+		if sep == ',' {
+			length += len(second)
+		}
+		if outer+length > maxAlgorithmLength {
+			return nil, &NotationError{Pos: start, Token: string(open), Msg: "algorithm too long from"}
+		}
+		if sep == ',' {
+			ret = Commutator(first, second)
+		} else {
+			ret = second.Conjugate(first)
+		}
+	}
+	p.pos++
+
+	// Suffixes: a repetition and/or a prime
+	digits := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos > digits {
+		k, err := strconv.Atoi(p.s[digits:p.pos])
+		if err != nil || k > (maxAlgorithmLength-outer)/max(len(ret), 1) {
+			return nil, &NotationError{Pos: digits, Token: p.s[digits:p.pos], Msg: "invalid repetition"}
+		}
+		ret = ret.Repeat(k)
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+		ret = ret.Inverse()
+		p.pos++
+	} else if strings.HasPrefix(p.s[p.pos:], "’") {
+		ret = ret.Inverse()
+		p.pos += len("’")
+	}
+	return ret, nil
+}
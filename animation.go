@@ -0,0 +1,106 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// MoveFrames returns steps+1 flats showing the move being performed: the
+// first is the cube, the last is the cube after Move, and those in between
+// slide the stickers that move along the net, a fraction of the way each.
+// Stickers travel along rows, then columns, from one place to the next,
+// so the frames show the stickers of a layer sliding in a ring.
+func (cube Cube) MoveFrames(m Move, steps int) []Flat {
+	return cube.frames(m, steps, false)
+}
+
+// TurnFrames is like MoveFrames but uses Turn instead of Move
+func (cube Cube) TurnFrames(m Move, steps int) []Flat {
+	return cube.frames(m, steps, true)
+}
+
+func (cube Cube) frames(m Move, steps int, isTurn bool) []Flat {
+	steps = max(steps, 1)
+	var start, end Flat
+	start.PaintCube(cube)
+	if isTurn {
+		end.PaintCube(cube.Turn(m))
+	} else {
+		end.PaintCube(cube.Move(m))
+	}
+	perm := cube.flatPermutation(m, isTurn)
+
+	// The cycles of the permutation, in a fixed order so that the frames
+	// are the same every time
+	visited := make(map[[2]int]bool)
+	var cycles [][][2]int
+	for _, first := range slices.SortedFunc(maps.Keys(perm), func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	}) {
+		var cycle [][2]int
+		for p := first; !visited[p]; p = perm[p] {
+			visited[p] = true
+			cycle = append(cycle, p)
+		}
+		if len(cycle) > 1 {
+			cycles = append(cycles, cycle)
+		}
+	}
+
+	// The ring of each cycle: the places its stickers go through, and
+	// where in the ring the stickers start
+	rings := make([][][2]int, len(cycles))
+	starts := make([][]int, len(cycles))
+	for i, cycle := range cycles {
+		for k, from := range cycle {
+			to := cycle[(k+1)%len(cycle)]
+			starts[i] = append(starts[i], len(rings[i]))
+			r, c := from[0], from[1]
+			for r != to[0] {
+				rings[i] = append(rings[i], [2]int{r, c})
+				if r < to[0] {
+					r++
+				} else {
+					r--
+				}
+			}
+			for c != to[1] {
+				rings[i] = append(rings[i], [2]int{r, c})
+				if c < to[1] {
+					c++
+				} else {
+					c--
+				}
+			}
+		}
+	}
+
+	ret := []Flat{start}
+	for step := 1; step < steps; step++ {
+		fl := start.Copy()
+		for _, cycle := range cycles {
+			for _, p := range cycle {
+				fl[p[0]][p[1]] = " "
+			}
+		}
+		for i, cycle := range cycles {
+			size := len(rings[i])
+			shift := int(float64(step)*float64(size)/float64(len(cycle))/float64(steps) + 0.5)
+			for k, p := range cycle {
+				color := start[p[0]][p[1]]
+				if color == " " || color == "" {
+					continue
+				}
+				to := rings[i][(starts[i][k]+shift)%size]
+				fl[to[0]][to[1]] = color
+			}
+		}
+		ret = append(ret, fl)
+	}
+	return append(ret, end)
+}
@@ -1,7 +1,12 @@
-package internal
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
 
 import "fmt"
 
+// Axis is one of the three axes a layer can be turned about.
 type Axis uint
 
 const (
@@ -15,6 +20,7 @@ func (ax Axis) String() string {
 	return names[ax]
 }
 
+// ParseAxis is the inverse of Axis.String
 func ParseAxis(str string) (Axis, error) {
 	for i, name := range [...]string{"x", "y", "z"} {
 		if str == name {
@@ -0,0 +1,311 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"context"
+	"sync"
+)
+
+// BidirectionalSolver finds shortest paths between two arbitrary cubes of
+// any size.  It searches breadth first from both cubes at once, one depth
+// at a time on the side with the smaller frontier, until the two searches
+// meet.  A path of length d is then found after visiting the states of
+// depth about d/2 around both cubes, instead of all the states of depth d
+// around the start cube.
+//
+// Every layer is turned, not only the faces, and turning an inner layer
+// counts as one move, as in the slice turn metric.  Paths are shortest in
+// that sense: Metric only decides whether a half turn counts as one move
+// or two.  A path with slice moves may thus be longer, counting its slice
+// moves as two face turns, than the paths other solvers find in HTM or
+// QTM.
+//
+// Cubes that differ only by their orientation are considered equal.  The
+// search keeps the corner at DBL in place: the search from the end cube
+// starts from its rotation that has the same corner at DBL as the start
+// cube, and turning the left, back or down face is searched as turning
+// every other layer the other way, which leaves the cube in the same state
+// up to a rotation.  The path returned turns the face.
+//
+// The number of states visited grows exponentially with the length of
+// the path, so the solver is only practical for cubes that are a few moves
+// apart.  GetPath returns nil if no path of at most MaxLength moves is
+// found.
+type BidirectionalSolver struct {
+	// MaxLength is the longest path looked for, counted as paths are.
+	// Zero means DefaultBidirectionalLength.
+	MaxLength int
+
+	// Metric decides how half turns are counted
+	Metric Metric
+
+	// Workers is the number of goroutines expanding the searches in
+	// parallel.  Zero means one per CPU.
+	Workers int
+}
+
+// DefaultBidirectionalLength is the longest path BidirectionalSolver looks
+// for by default
+const DefaultBidirectionalLength = 8
+
+// How a state was first reached
+type bidiNode struct {
+	parent string   // the key of the state it was reached from
+	turn   faceTurn // the turn that reached it, from the parent
+	depth  int
+}
+
+// One of the two searches
+type bidiSide struct {
+	seen     map[string]bidiNode
+	frontier []Cube // the states of the deepest depth, in the order found
+	depth    int
+}
+
+func newBidiSide(root Cube) *bidiSide {
+	return &bidiSide{
+		seen:     map[string]bidiNode{root.Key(): {}},
+		frontier: []Cube{root},
+	}
+}
+
+// Returns the turns from the root of a side to a state
+func (side *bidiSide) turns(key string) []faceTurn {
+	var ret []faceTurn
+	for node := side.seen[key]; node.depth > 0; node = side.seen[node.parent] {
+		ret = append(ret, node.turn)
+	}
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
+
+type bidiSearch struct {
+	n         uint
+	ctx       context.Context
+	turns     []faceTurn
+	halfTurns bool // whether turns has the half turns
+	workers   int
+	nodes     int64
+}
+
+// Applies a turn, turning the other layers instead of those at -n/2
+func (s *bidiSearch) apply(cube Cube, ft faceTurn) Cube {
+	h := int(s.n / 2)
+	cube = cube.Copy()
+	for _, m := range ft.moves() {
+		if m.Idx != -h {
+			cube.Apply(m)
+			continue
+		}
+		for idx := -h + 1; idx <= h; idx++ {
+			if s.n%2 == 1 || idx != 0 {
+				cube.Apply(Move{Axis: m.Axis, Idx: idx, Direction: !m.Direction})
+			}
+		}
+	}
+	return cube
+}
+
+// A state reached while expanding a frontier, not yet merged into the
+// states seen
+type bidiChild struct {
+	cube   Cube
+	key    string
+	parent string
+	turn   faceTurn
+}
+
+// Turns every state of a part of a frontier, keeping the children that
+// the side has not seen.  The side is only read.
+func (s *bidiSearch) children(side *bidiSide, frontier []Cube) ([]bidiChild, int64, error) {
+	var ret []bidiChild
+	var nodes int64
+	for _, cube := range frontier {
+		key := cube.Key()
+		last := side.seen[key]
+		for _, ft := range s.turns {
+			// Turning a face twice in a row is one turn, or none
+			if last.depth > 0 && ft.face == last.turn.face && (s.halfTurns || ft.times+last.turn.times == 4) {
+				continue
+			}
+			nodes++
+			if nodes%1024 == 0 {
+				if err := s.ctx.Err(); err != nil {
+					return nil, nodes, err
+				}
+			}
+			child := s.apply(cube, ft)
+			childKey := child.Key()
+			if _, ok := side.seen[childKey]; ok {
+				continue
+			}
+			ret = append(ret, bidiChild{child, childKey, key, ft})
+		}
+	}
+	return ret, nodes, nil
+}
+
+// Expands the frontier of a side by one depth.  Returns the first state
+// found that the other side has seen, if any, once the whole depth has
+// been expanded.
+//
+// The frontier is split between the workers, which turn its states in
+// parallel.  The children are then merged in the order of the frontier,
+// so the states seen, and the path found, are the same whatever the
+// number of workers.
+func (s *bidiSearch) expand(side, other *bidiSide) (string, bool, error) {
+	workers := min(s.workers, len(side.frontier))
+	parts := make([][]bidiChild, workers)
+	errs := make([]error, workers)
+	nodes := make([]int64, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		lo, hi := w*len(side.frontier)/workers, (w+1)*len(side.frontier)/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parts[w], nodes[w], errs[w] = s.children(side, side.frontier[lo:hi])
+		}()
+	}
+	wg.Wait()
+	for w := range workers {
+		s.nodes += nodes[w]
+		if errs[w] != nil {
+			return "", false, errs[w]
+		}
+	}
+
+	var next []Cube
+	meet, met := "", false
+	side.depth++
+	for _, part := range parts {
+		for _, child := range part {
+			if _, ok := side.seen[child.key]; ok {
+				continue
+			}
+			side.seen[child.key] = bidiNode{parent: child.parent, turn: child.turn, depth: side.depth}
+			next = append(next, child.cube)
+			if _, ok := other.seen[child.key]; ok && !met {
+				meet, met = child.key, true
+			}
+		}
+	}
+	side.frontier = next
+	return meet, met, nil
+}
+
+func (s BidirectionalSolver) GetPath(start Cube, end Cube) []Move {
+	path, err := s.Search(context.Background(), start, end, Options{MaxDepth: s.MaxLength, Metric: s.Metric, Workers: s.Workers})
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// Search looks for a shortest path of at most opts.MaxDepth moves, or of
+// the solver's MaxLength if zero, with slice moves counting as one move
+// and half turns as opts.Metric counts them, the solver's Metric being
+// ignored.  Progress is reported each time a side deepens, with the sum
+// of the depths of both sides.  The path is the same whatever the number
+// of workers.
+func (s BidirectionalSolver) Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error) {
+	if opts.Metric < HTM || opts.Metric > QTM {
+		return nil, ErrMetric
+	}
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+	if start.n != end.n {
+		return nil, ErrNoPath
+	}
+	maxLength := opts.MaxDepth
+	if maxLength <= 0 {
+		maxLength = s.MaxLength
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultBidirectionalLength
+	}
+
+	// The end cube, turned so that its DBL corner matches the start cube's
+	h := int(start.n / 2)
+	dbl := func(cube Cube) cubi {
+		for _, cbi := range cube.cubis {
+			if cbi.pv == (vec{-h, -h, -h}) {
+				return cbi
+			}
+		}
+		return cubi{}
+	}
+	found := false
+	for _, rotated := range end.GetAllRotations() {
+		if dbl(rotated) == dbl(start) {
+			end, found = rotated, true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNoPath
+	}
+
+	// On the pocket cube, turning the left face is turning the right face
+	search := bidiSearch{n: start.n, ctx: ctx, halfTurns: opts.Metric == HTM, workers: opts.workers()}
+	first := -h
+	if start.n == 2 {
+		first = 1
+	}
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		for idx := first; idx <= h; idx++ {
+			if start.n%2 == 0 && idx == 0 {
+				continue
+			}
+			face := Move{Axis: ax, Idx: idx, Direction: Clock}
+			search.turns = append(search.turns, faceTurn{face, 1})
+			if opts.Metric == HTM {
+				search.turns = append(search.turns, faceTurn{face, 2})
+			}
+			search.turns = append(search.turns, faceTurn{face, 3})
+		}
+	}
+
+	forward, backward := newBidiSide(start), newBidiSide(end)
+	meet, met := start.Key(), start.Equal(end)
+	for !met {
+		if forward.depth+backward.depth >= maxLength || len(forward.frontier) == 0 || len(backward.frontier) == 0 {
+			return nil, ErrNoPath
+		}
+		var err error
+		if len(forward.frontier) <= len(backward.frontier) {
+			meet, met, err = search.expand(forward, backward)
+		} else {
+			meet, met, err = search.expand(backward, forward)
+		}
+		if err != nil {
+			return nil, err
+		}
+		opts.progress(forward.depth+backward.depth, search.nodes)
+	}
+
+	// The turns from the start cube, then those from the end cube undone.
+	// Turning a face at -n/2 leaves the cube rotated compared to the
+	// search, and the following moves are rotated alike.
+	turns := forward.turns(meet)
+	back := backward.turns(meet)
+	for i := len(back) - 1; i >= 0; i-- {
+		turns = append(turns, faceTurn{back[i].face, 4 - back[i].times})
+	}
+	path := []Move{}
+	r := cubeRotations()[0]
+	for _, ft := range turns {
+		for _, m := range ft.moves() {
+			path = append(path, m.transform(r))
+			if m.Idx == -h {
+				r = r.times(getRotationMatrix(m.Axis, m.Direction))
+			}
+		}
+	}
+	return path, nil
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"testing"
 
-	. "github.com/dfava/cube/internal"
+	. "github.com/dfava/cube"
 	"github.com/stretchr/testify/require"
 )
 
@@ -8,28 +8,30 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 	"golang.org/x/term"
 )
 
 const animSpeed = 250 * time.Millisecond
 
 type Animator interface {
-	Animate(cb internal.Cube, ax internal.Axis, idx int, dir internal.Direction, n uint, helpVisible bool)
+	// Animate shows a move being performed on cb, as Turn does if turn is
+	// set and as Move does otherwise
+	Animate(cb cube.Cube, ax cube.Axis, idx int, dir cube.Direction, turn bool, n uint, helpVisible bool)
 }
 
 type move struct {
-	axis internal.Axis
+	axis cube.Axis
 	idx  int
-	dir  internal.Direction
+	dir  cube.Direction
+	turn bool // performed with Turn, keeping the centers in place
 	desc string
 }
 
 func main() {
-	internal.PrintInColors(true)
 	var n uint = 3
-	cb := internal.New(n)
-	history := []internal.Cube{cb}
+	cb := cube.New(n)
+	history := []cube.Cube{cb}
 	moves := []move{}
 	var animator Animator = DummyAnimator{}
 
@@ -52,6 +54,10 @@ func main() {
 	printHelp(n)
 	helpVisible := true
 	showCube := true
+	note := "" // shown below the cube, once
+	view := "flat"
+	isoOpts := cube.IsometricOptions{}
+	textOpts := cube.TextOptions{Mode: cube.ANSI256}
 
 	for {
 		if showCube {
@@ -60,7 +66,18 @@ func main() {
 				printHelp(n)
 			}
 			printAxes()
-			fmt.Printf("\r\nCube state (moves: %d):\r\n%s\r\n", len(history)-1, history[len(history)-1])
+			current := history[len(history)-1]
+			if view == "iso" {
+				if pic, err := current.Isometric(isoOpts); err == nil {
+					fmt.Printf("\r\nCube state (moves: %d):\r\n%s\r\n", len(history)-1, pic)
+				}
+			} else {
+				fmt.Printf("\r\nCube state (moves: %d):\r\n%s\r\n", len(history)-1, current.Text(textOpts))
+			}
+			if note != "" {
+				fmt.Printf("\r\n%s\r\n", strings.ReplaceAll(note, "\n", "\r\n"))
+				note = ""
+			}
 		}
 		showCube = true
 
@@ -103,8 +120,8 @@ func main() {
 				continue
 			}
 			n = uint(newSize)
-			cb = internal.New(n)
-			history = []internal.Cube{cb}
+			cb = cube.New(n)
+			history = []cube.Cube{cb}
 			moves = []move{}
 			helpVisible = true
 			fmt.Printf("Created a new %dx%d cube.\r\n", n, n)
@@ -112,8 +129,8 @@ func main() {
 			fmt.Println("Goodbye!\r")
 			return
 		case "r", "reset":
-			cb = internal.New(n)
-			history = []internal.Cube{cb}
+			cb = cube.New(n)
+			history = []cube.Cube{cb}
 			moves = []move{}
 			helpVisible = true
 			fmt.Println("Cube reset.\r")
@@ -129,12 +146,9 @@ func main() {
 			current := history[len(history)-1]
 			for range 20 {
 				ax, idx, dir := randomMove(n)
-				current = current.Move(internal.Move{Axis: ax, Idx: idx, Direction: dir})
-				if n%2 == 1 && idx == 0 {
-					current = current.Rotate(ax, !dir)
-				}
+				current = current.Turn(cube.Move{Axis: ax, Idx: idx, Direction: dir})
 				history = append(history, current)
-				moves = append(moves, move{ax, idx, dir, fmt.Sprintf("shuffle %s %d %s", ax, idx, dir)})
+				moves = append(moves, move{ax, idx, dir, true, fmt.Sprintf("shuffle %s %d %s", ax, idx, dir)})
 			}
 			fmt.Println("Cube shuffled (20 moves added to history).\r")
 		case "p", "playback":
@@ -146,7 +160,7 @@ func main() {
 			fmt.Println("Playing back history:\r")
 			for i, m := range moves {
 				fmt.Printf("Step %d: %s\r\n", i+1, m.desc)
-				animator.Animate(history[i], m.axis, m.idx, m.dir, n, helpVisible)
+				animator.Animate(history[i], m.axis, m.idx, m.dir, m.turn, n, helpVisible)
 				time.Sleep(3 * animSpeed)
 			}
 		case "x", "y", "z":
@@ -155,7 +169,7 @@ func main() {
 				showCube = false
 				continue
 			}
-			ax, _ := internal.ParseAxis(cmd)
+			ax, _ := cube.ParseAxis(cmd)
 			idx, err := strconv.Atoi(parts[1])
 			if err != nil {
 				fmt.Printf("Invalid index: %s\r\n", parts[1])
@@ -173,12 +187,12 @@ func main() {
 				showCube = false
 				continue
 			}
-			var dir internal.Direction
+			var dir cube.Direction
 			dirPart := strings.ToLower(parts[2])
 			if dirPart == "c" {
-				dir = internal.Clock
+				dir = cube.Clock
 			} else if dirPart == "cc" {
-				dir = internal.Counterclock
+				dir = cube.Counterclock
 			} else {
 				fmt.Printf("Invalid direction: %s. Use 'c' or 'cc'.\r\n", parts[2])
 				showCube = false
@@ -186,13 +200,76 @@ func main() {
 			}
 
 			current := history[len(history)-1]
-			next := current.Move(internal.Move{Axis: ax, Idx: idx, Direction: dir})
-			if n%2 == 1 && idx == 0 {
-				next = next.Rotate(ax, !dir)
-			}
-			animator.Animate(current, ax, idx, dir, n, helpVisible)
+			next := current.Turn(cube.Move{Axis: ax, Idx: idx, Direction: dir})
+			animator.Animate(current, ax, idx, dir, true, n, helpVisible)
 			history = append(history, next)
-			moves = append(moves, move{ax, idx, dir, fmt.Sprintf("%s %d %s", ax, idx, dir)})
+			moves = append(moves, move{ax, idx, dir, true, fmt.Sprintf("%s %d %s", ax, idx, dir)})
+		case "a", "alg":
+			if len(parts) < 2 {
+				fmt.Println("Invalid algorithm. Usage: alg <moves>, for example: alg R U R' U'\r")
+				showCube = false
+				continue
+			}
+			notation := strings.TrimSpace(input[len(parts[0]):])
+			ms, err := cube.ParseMoves(n, notation)
+			if err != nil {
+				fmt.Printf("Invalid algorithm: %v\r\n", err)
+				showCube = false
+				continue
+			}
+			// Slice moves and rotations turn the middle layer with its
+			// centers, so moves are performed as they are written
+			for _, m := range ms {
+				current := history[len(history)-1]
+				next := current.Move(m)
+				animator.Animate(current, m.Axis, m.Idx, m.Direction, false, n, helpVisible)
+				history = append(history, next)
+				moves = append(moves, move{m.Axis, m.Idx, m.Direction, false, fmt.Sprintf("%s (%s)", cube.FormatMoves(n, []cube.Move{m}), m)})
+			}
+		case "v", "view":
+			if len(parts) < 2 || (parts[1] != "flat" && parts[1] != "iso") {
+				fmt.Println("Invalid view. Usage: view flat, or view iso [rotations], for example: view iso y2\r")
+				showCube = false
+				continue
+			}
+			if parts[1] == "iso" {
+				opts := cube.IsometricOptions{View: strings.Join(parts[2:], " ")}
+				if _, err := history[len(history)-1].Isometric(opts); err != nil {
+					fmt.Printf("Invalid view: %v\r\n", err)
+					showCube = false
+					continue
+				}
+				isoOpts = opts
+			}
+			view = parts[1]
+		case "l", "learn":
+			if n != 3 {
+				fmt.Println("Learning mode is only available for 3x3 cubes.\r")
+				showCube = false
+				continue
+			}
+			current := history[len(history)-1]
+			stages := cube.LayerSolver{}.Stages(current, cube.New(n))
+			if stages == nil {
+				fmt.Println("This cube cannot be solved.\r")
+				showCube = false
+				continue
+			}
+			note = "The cube is solved!"
+			for i, stage := range stages {
+				if len(stage.Moves) == 0 {
+					continue
+				}
+				note = fmt.Sprintf("Stage %d of %d, %s: %s\n%s", i+1, len(stages), stage.Name, cube.FormatMoves(n, stage.Moves), stage.Explanation)
+				for _, m := range stage.Moves {
+					current := history[len(history)-1]
+					next := current.Move(m)
+					animator.Animate(current, m.Axis, m.Idx, m.Direction, false, n, helpVisible)
+					history = append(history, next)
+					moves = append(moves, move{m.Axis, m.Idx, m.Direction, false, fmt.Sprintf("%s: %s (%s)", stage.Name, cube.FormatMoves(n, []cube.Move{m}), m)})
+				}
+				break
+			}
 		default:
 			fmt.Printf("Unknown command: %s. Type 'h' for help.\r\n", cmd)
 			helpVisible = false
@@ -207,6 +284,9 @@ func printHelp(n uint) {
 	fmt.Println("  x <idx> <c|cc>  : Turn about X-axis at index <idx> (c: clockwise, cc: counter-clockwise)\r")
 	fmt.Println("  y <idx> <c|cc>  : Turn about Y-axis at index <idx>\r")
 	fmt.Println("  z <idx> <c|cc>  : Turn about Z-axis at index <idx>\r")
+	fmt.Println("  a, alg <moves>  : Perform moves in Singmaster notation, e.g. alg R U R' U2\r")
+	fmt.Println("  l, learn        : Solve the next stage of a 3x3 cube, layer by layer, and explain it\r")
+	fmt.Println("  v, view <flat|iso> [rotations] : Show the cube flat or in 3D, e.g. view iso y2\r")
 	// Added a small tip about history
 	fmt.Println("  [Up Arrow]      : Recall previous command\r")
 	fmt.Println("  u, undo         : Undo the last turn\r")
@@ -232,8 +312,8 @@ func printAxes() {
 	fmt.Println(" y\r")
 }
 
-func randomMove(n uint) (internal.Axis, int, internal.Direction) {
-	axes := [...]internal.Axis{internal.Xax, internal.Yax, internal.Zax}
+func randomMove(n uint) (cube.Axis, int, cube.Direction) {
+	axes := [...]cube.Axis{cube.Xax, cube.Yax, cube.Zax}
 	idxs := []int{}
 	for idx := -int(n) / 2; idx <= int(n)/2; idx++ {
 		if n%2 == 0 && idx == 0 {
@@ -241,7 +321,7 @@ func randomMove(n uint) (internal.Axis, int, internal.Direction) {
 		}
 		idxs = append(idxs, idx)
 	}
-	dirs := [...]internal.Direction{internal.Counterclock, internal.Clock}
+	dirs := [...]cube.Direction{cube.Counterclock, cube.Clock}
 
 	ax := axes[rand.Intn(len(axes))]
 	idx := idxs[rand.Intn(len(idxs))]
@@ -0,0 +1,80 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Color is the color of a sticker.  The sign of a Color in a color vector
+// tells on which side of an axis the sticker faces.
+type Color int
+
+const (
+	zero   Color = iota // the "zero color"
+	Green               // green
+	White               // white
+	Orange              // orange
+	Red                 // red
+	Yellow              // yellow
+	Blue                // blue
+)
+
+func (c Color) Abs() Color {
+	if c < 0 {
+		return -c
+	}
+	return c
+}
+
+// The colors as plain letters
+var colorLetters = [...]string{" ", "g", "w", "o", "r", "y", "b"}
+
+// String returns the letter of the color, with a minus sign for negative
+// colors.  Flat.Text and Cube.Text print the letters in color.
+func (c Color) String() string {
+	var str string
+	if c < 0 {
+		str = "-"
+		c = -c
+	}
+	return str + colorLetters[c]
+}
+
+var stringToColor = map[string]Color{
+	" ": zero,
+	"g": Green,
+	"w": White,
+	"o": Orange,
+	"r": Red,
+	"y": Yellow,
+	"b": Blue,
+}
+
+// Removes ANSI escape codes that set colors, \033[...m
+func stripColors(str string) string {
+	for {
+		start := strings.Index(str, "\033[")
+		if start < 0 {
+			return str
+		}
+		end := strings.IndexByte(str[start:], 'm')
+		if end < 0 {
+			return str
+		}
+		str = str[:start] + str[start+end+1:]
+	}
+}
+
+// ParseColor is the inverse of Color.String.  It also reads letters
+// colored with ANSI escape codes, whichever the colors.
+func ParseColor(str string) (Color, error) {
+	c, ok := stringToColor[stripColors(str)]
+	if !ok {
+		return c, fmt.Errorf("ParseColor %s", str)
+	}
+	return c, nil
+}
@@ -0,0 +1,503 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"sync"
+)
+
+type vec [3]int       // a vector in 3D
+type cVec [3]Color    // a "color vector
+type matrix [3][3]int // a matrix
+
+func (a cVec) or(b cVec) cVec {
+	var c cVec
+	or := func(a Color, b Color) Color {
+		if a == zero {
+			return b
+		}
+		return a
+	}
+	c[0] = or(a[0], b[0])
+	c[1] = or(a[1], b[1])
+	c[2] = or(a[2], b[2])
+	return c
+}
+
+// Multiplying a matrix by a cubi
+//
+// The function multiplies the matrix by the position vector and
+// it multiplies the matrix by the color vector
+func (m *matrix) mult(cbi cubi) cubi {
+	var ret cubi
+	for i := 0; i <= 2; i++ {
+		ret.pv[i] = m[i][0]*cbi.pv[0] + m[i][1]*cbi.pv[1] + m[i][2]*cbi.pv[2]
+		ret.cv[i] = Color(m[i][0]*int(cbi.cv[0]) + m[i][1]*int(cbi.cv[1]) + m[i][2]*int(cbi.cv[2]))
+	}
+	return ret
+}
+
+type cubi struct {
+	pv vec  // position vector
+	cv cVec // color vector
+}
+
+// Cube is a Rubik's cube of size n x n x n.  The zero value is not
+// usable, cubes are created with New.
+type Cube struct {
+	n     uint
+	cubis []cubi // in the order of their positions, see cubiIndex
+}
+
+// Maps a coordinate to 0..n-1
+func coordIndex(n uint, x int) int {
+	h := int(n / 2)
+	if n%2 == 0 && x > 0 {
+		return x + h - 1
+	}
+	return x + h
+}
+
+// Maps 0..n-1 to a coordinate
+func coordValue(n uint, i int) int {
+	h := int(n / 2)
+	if n%2 == 0 && i >= h {
+		return i - h + 1
+	}
+	return i - h
+}
+
+// Returns the index of the cubi at a position.  Cubis are kept in the
+// order Reset puts them in: by x, then y, then z, leaving out the
+// positions inside the cube.  The outer layers of x hold n*n cubis, the
+// inner ones a ring of 4n-4.
+func cubiIndex(n uint, p vec) int {
+	size, last := int(n), int(n)-1
+	x, y, z := coordIndex(n, p[Xax]), coordIndex(n, p[Yax]), coordIndex(n, p[Zax])
+	if x == 0 {
+		return y*size + z
+	}
+	base := size*size + (x-1)*(4*size-4)
+	switch {
+	case x == last:
+		return base + y*size + z
+	case y == 0:
+		return base + z
+	case y == last:
+		return base + size + 2*(size-2) + z
+	case z == 0:
+		return base + size + 2*(y-1)
+	default:
+		return base + size + 2*(y-1) + 1
+	}
+}
+
+// Calls f with the position of every cubi in a layer, in time
+// proportional to the size of the layer
+func eachInLayer(n uint, ax Axis, idx int, f func(vec)) {
+	h := int(n / 2)
+	if idx < -h || idx > h || (n%2 == 0 && idx == 0) {
+		return
+	}
+	u, v := (ax+1)%3, (ax+2)%3
+	var p vec
+	p[ax] = idx
+	for i := range int(n) {
+		p[u] = coordValue(n, i)
+		if idx == h || idx == -h || p[u] == h || p[u] == -h {
+			for j := range int(n) {
+				p[v] = coordValue(n, j)
+				f(p)
+			}
+			continue
+		}
+		for _, b := range [...]int{-h, h} {
+			p[v] = b
+			f(p)
+		}
+	}
+}
+
+// New returns a solved cube of size n, in the Western color scheme.  It
+// panics if n is smaller than two.
+func New(n uint) Cube {
+	return Western().New(n)
+}
+
+// A copy-constructor
+func (cube Cube) Copy() Cube {
+	var ret Cube
+	ret.n = cube.n
+	ret.cubis = make([]cubi, len(cube.cubis))
+	copy(ret.cubis, cube.cubis)
+	return ret
+}
+
+// Resets the cube to the Western color scheme:
+// green  at the center   (y>0)
+// blue   to the back     (y<0)
+// red    to the left     (x<0)
+// orange to the right    (x>0)
+// yellow upward          (z>0)
+// white  downward        (z<0)
+//
+// Cannot handle the trivial 1x1 cube.  See ColorScheme.Reset for other
+// color schemes.
+func (cube *Cube) Reset() {
+	Western().Reset(cube)
+}
+
+// A string representation of a Rubik's cube
+func (cube Cube) String() string {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl.String()
+}
+
+// Text prints the cube opened up as a Flat.  See Flat.Text.
+func (cube Cube) Text(opts TextOptions) string {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl.Text(opts)
+}
+
+// GetSize returns n for a cube of size n x n x n
+func (cube Cube) GetSize() uint {
+	return cube.n
+}
+
+// Move turns the layer at index Idx along Axis by 90 degrees in Direction.
+// Valid indices range from -n/2 to n/2, excluding 0 on cubes of even size.
+type Move struct {
+	Axis      Axis
+	Idx       int
+	Direction Direction
+}
+
+func (m Move) String() string {
+	return fmt.Sprintf("%s %d %s", m.Axis, m.Idx, m.Direction)
+}
+
+// The cubis a quarter turn of a layer moves, clockwise
+type layerCycles struct {
+	cycles [][4]int32 // the cubi at cycles[i][k] goes to cycles[i][(k+1)%4]
+	fixed  []int32    // cubis that turn in place, at the center of the layer
+}
+
+// The layers of a cube of some size, by axis and coordIndex
+type layerTable [3][]layerCycles
+
+var layerTables sync.Map // size to *layerTable
+
+func getLayerTable(n uint) *layerTable {
+	if t, ok := layerTables.Load(n); ok {
+		return t.(*layerTable)
+	}
+	t := new(layerTable)
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		mat := getRotationMatrix(ax, Clock)
+		t[ax] = make([]layerCycles, n)
+		for i := range t[ax] {
+			l := &t[ax][i]
+			seen := make(map[vec]bool)
+			eachInLayer(n, ax, coordValue(n, i), func(p vec) {
+				if seen[p] {
+					return
+				}
+				var cycle [4]int32
+				for k := range cycle {
+					seen[p] = true
+					cycle[k] = int32(cubiIndex(n, p))
+					p = mat.mult(cubi{pv: p}).pv
+				}
+				if cycle[1] == cycle[0] {
+					l.fixed = append(l.fixed, cycle[0])
+				} else {
+					l.cycles = append(l.cycles, cycle)
+				}
+			})
+		}
+	}
+	actual, _ := layerTables.LoadOrStore(n, t)
+	return actual.(*layerTable)
+}
+
+// Performs a move on a cube by turning part of the
+// cube about an Axis in a particular direction
+func (cube Cube) Move(m Move) Cube {
+	ret := cube.Copy()
+	ret.Apply(m)
+	return ret
+}
+
+// Apply performs a move in place.  It does not allocate: the cubis of
+// the layer are permuted along cycles computed once per size of cube.
+func (cube *Cube) Apply(m Move) {
+	h := int(cube.n / 2)
+	if m.Idx < -h || m.Idx > h || (cube.n%2 == 0 && m.Idx == 0) {
+		return
+	}
+	l := &getLayerTable(cube.n)[m.Axis][coordIndex(cube.n, m.Idx)]
+	mat := getRotationMatrix(m.Axis, m.Direction)
+	cs := cube.cubis
+	for _, i := range l.fixed {
+		cs[i] = mat.mult(cs[i])
+	}
+	for _, c := range l.cycles {
+		if m.Direction == Clock {
+			cs[c[0]], cs[c[1]], cs[c[2]], cs[c[3]] = mat.mult(cs[c[3]]), mat.mult(cs[c[0]]), mat.mult(cs[c[1]]), mat.mult(cs[c[2]])
+		} else {
+			cs[c[0]], cs[c[1]], cs[c[2]], cs[c[3]] = mat.mult(cs[c[1]]), mat.mult(cs[c[2]]), mat.mult(cs[c[3]]), mat.mult(cs[c[0]])
+		}
+	}
+}
+
+// ApplyAll performs the moves in order, in place
+func (cube *Cube) ApplyAll(ms []Move) {
+	for _, m := range ms {
+		cube.Apply(m)
+	}
+}
+
+// Turn performs a move like Move does, except that turning the middle
+// layer of an odd sized cube leaves the centers where they were.
+//
+// Turning the middle layer is the same as turning the two outer layers
+// the other way and then rotating the whole cube, Turn leaves out the
+// rotation.  Cubes that are canonical stay canonical.
+func (cube Cube) Turn(m Move) Cube {
+	ret := cube.Move(m)
+	if cube.n%2 == 1 && m.Idx == 0 {
+		// Preserves a canonical cube
+		ret = ret.Rotate(m.Axis, !m.Direction)
+	}
+	return ret
+}
+
+// MoveAll performs the moves in order and returns the final cube
+func (cube Cube) MoveAll(ms []Move) Cube {
+	ret := cube.Copy()
+	ret.ApplyAll(ms)
+	return ret
+}
+
+// TurnAll is like MoveAll but uses Turn instead of Move
+func (cube Cube) TurnAll(ms []Move) Cube {
+	for _, m := range ms {
+		cube = cube.Turn(m)
+	}
+	return cube
+}
+
+// States yields the cube after each of the moves, performed in order
+func (cube Cube) States(ms []Move) iter.Seq[Cube] {
+	return func(yield func(Cube) bool) {
+		c := cube
+		for _, m := range ms {
+			c = c.Move(m)
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// TurnStates is like States but uses Turn instead of Move
+func (cube Cube) TurnStates(ms []Move) iter.Seq[Cube] {
+	return func(yield func(Cube) bool) {
+		c := cube
+		for _, m := range ms {
+			c = c.Turn(m)
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// Moves returns the cube after each of the moves, performed in order,
+// similar to a map function
+func (cube Cube) Moves(ms []Move) []Cube {
+	ret := make([]Cube, 0, len(ms))
+	for next := range cube.States(ms) {
+		ret = append(ret, next)
+	}
+	return ret
+}
+
+// Perform all moves on a cube
+func (cube Cube) GetAllTurns() []Cube {
+	var ret []Cube
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		for idx := -int(cube.n) / 2; idx <= int(cube.n)/2; idx++ {
+			for _, dir := range [...]Direction{Counterclock, Clock} {
+				if cube.n%2 == 0 && idx == 0 {
+					continue
+				}
+				ret = append(ret, cube.Turn(Move{Axis: ax, Idx: idx, Direction: dir}))
+			}
+		}
+	}
+	return ret
+}
+
+// Rotate the whole cube in 3 dimensions
+func (cube Cube) Rotate(a Axis, counter Direction) Cube {
+	ret := cube.Copy()
+	m := getRotationMatrix(a, counter)
+	for _, cbi := range cube.cubis {
+		// We rotate via matrix multiplication
+		cbi = m.mult(cbi)
+		ret.cubis[cubiIndex(cube.n, cbi.pv)] = cbi
+	}
+	return ret
+}
+
+// Perform all rotations on a cube
+// There are 24 equivalence classes (rotations) of a cube.
+func (cube Cube) GetAllRotations() []Cube {
+	var ret []Cube
+
+	current := cube
+	explored := make(map[string]Cube)
+	var toExplore []Cube
+
+	for {
+		explored[current.Key()] = current
+
+		for _, ax := range [...]Axis{Xax, Yax, Zax} {
+			for _, dir := range [...]Direction{Counterclock, Clock} {
+				other := current.Rotate(ax, dir)
+				if _, in := explored[other.Key()]; !in {
+					toExplore = append(toExplore, other)
+				}
+			}
+		}
+
+		if len(toExplore) == 0 {
+			// Nothing more to explore
+			for k := range explored {
+				ret = append(ret, explored[k])
+			}
+			return ret
+		}
+
+		current = toExplore[0]
+		toExplore = toExplore[1:]
+	}
+}
+
+// Shuffle performs a number of random moves on the cube.
+// The orientation of odd sized cubes is preserved.
+func (cube *Cube) Shuffle(times uint) {
+	axes := [...]Axis{Xax, Yax, Zax}
+	idxs := make([]int, cube.n+(cube.n+1)%2)
+	for idx := -int(cube.n) / 2; idx <= int(cube.n)/2; idx++ {
+		idxs[idx+int(cube.n)/2] = idx
+	}
+	dirs := [...]Direction{Counterclock, Clock}
+	var perms uint
+	for perms < times {
+		ax := axes[rand.Intn(len(axes))]  // pick an axis
+		idx := idxs[rand.Intn(len(idxs))] // pick an index
+		dir := dirs[rand.Intn(len(dirs))] // pick a direction
+		if cube.n%2 == 0 && idx == 0 {
+			continue
+		}
+		(*cube) = cube.Turn(Move{Axis: ax, Idx: idx, Direction: dir}) // Preserve the cube's orientation
+		perms += 1
+	}
+}
+
+// IsSolved tells whether every side of the cube has a single color,
+// regardless of how the cube is oriented
+func (cube Cube) IsSolved() bool {
+	// We could do with [3][2]Color, I'm wasting a bit of memory to simplify
+	// the algorithm:
+	// Signs -1 and 1 map to array indexes 0 and 2 as opposed to 0 and 1
+	var sideColor [3][3]Color
+	extremity := int((cube.n) / 2)
+	for idx := range cube.cubis {
+		for _, ax := range [...]Axis{Xax, Yax, Zax} {
+			for sign := range []int{-1, 1} {
+				if cube.cubis[idx].pv[ax] == sign*extremity {
+					if sideColor[ax][sign+1] == zero {
+						sideColor[ax][sign+1] = cube.cubis[idx].cv[ax]
+					} else if sideColor[ax][sign+1] != cube.cubis[idx].cv[ax] {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// IsCanonical tells whether the centers of an odd sized cube are where
+// Reset puts them.  See ColorScheme.IsCanonical for other color schemes.
+func (cube Cube) IsCanonical() bool {
+	return Western().IsCanonical(cube)
+}
+
+// Returns where the sticker of a cubi facing along an axis is on a Flat
+func flatPosition(n uint, cbi cubi, a Axis) [2]int {
+	proj := project(n, a, cbi.cv[a] > 0)
+	r := int(n/2) + proj.sign[row]*cbi.pv[proj.axis[row]] + proj.offset[row]
+	c := int(n/2) + proj.sign[col]*cbi.pv[proj.axis[col]] + proj.offset[col]
+	if n%2 == 0 {
+		signArray := [3]float64{
+			float64(getSign(cbi.pv[Xax])),
+			float64(getSign(cbi.pv[Yax])),
+			float64(getSign(cbi.pv[Zax]))}
+		r += proj.sign[row] * int(proj.fun[row](-signArray[proj.axis[row]]*0.5))
+		c += proj.sign[col] * int(proj.fun[col](-signArray[proj.axis[col]]*0.5))
+	}
+	return [2]int{r, c}
+}
+
+// GetFlatPermutation returns where each sticker of a Flat goes when the
+// move is performed with Turn.  Keys and values are (row, column) pairs.
+func (cube Cube) GetFlatPermutation(ax Axis, idx int, dir Direction) map[[2]int][2]int {
+	return cube.flatPermutation(Move{Axis: ax, Idx: idx, Direction: dir}, true)
+}
+
+// Returns where each sticker of a Flat goes when the move is performed
+// with Turn, or with Move if turn is false
+func (cube Cube) flatPermutation(m Move, isTurn bool) map[[2]int][2]int {
+	n := cube.n
+	mat := getRotationMatrix(m.Axis, m.Direction)
+	rot := getRotationMatrix(m.Axis, !m.Direction)
+	turn := func(cbi cubi) cubi {
+		if cbi.pv[m.Axis] == m.Idx {
+			cbi = mat.mult(cbi)
+		}
+		if isTurn && n%2 == 1 && m.Idx == 0 {
+			cbi = rot.mult(cbi)
+		}
+		return cbi
+	}
+
+	// Each sticker is followed on its own, to know where it ends up
+	permutation := make(map[[2]int][2]int)
+	for _, cbi := range cube.cubis {
+		for a := range cbi.cv {
+			if cbi.cv[a] == 0 {
+				continue
+			}
+			sticker := cubi{pv: cbi.pv}
+			sticker.cv[a] = cbi.cv[a]
+			next := turn(sticker)
+			for b := range next.cv {
+				if next.cv[b] != 0 {
+					permutation[flatPosition(n, cbi, Axis(a))] = flatPosition(n, next, Axis(b))
+				}
+			}
+		}
+	}
+	return permutation
+}
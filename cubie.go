@@ -0,0 +1,480 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+// Solvers work on a more compact representation than a list of cubis:
+// for every slot a piece can be in, which piece is there and how it is
+// twisted.  This is the "cubie" level of Kociemba's two-phase algorithm,
+// and we follow its conventions so its literature applies.
+//
+// Slots are named after the faces they touch:
+//
+//	U up (z>0)     D down (z<0)
+//	R right (x>0)  L left (x<0)
+//	F front (y>0)  B back (y<0)
+
+// The colors of the six faces, indexed by axis and by side: 0 for the
+// negative end of the axis, 1 for the positive end
+type faceColors [3][2]Color
+
+// The side of an axis a coordinate is on
+func side(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Corner slots, in Kociemba's order
+const (
+	cURF = iota
+	cUFL
+	cULB
+	cUBR
+	cDFR
+	cDLF
+	cDBL
+	cDRB
+	nCorners
+)
+
+type cornerSlot struct {
+	pos  vec     // the signs of the slot's coordinates
+	axes [3]Axis // the slot's faces, clockwise, starting from U or D
+}
+
+var cornerSlots = [nCorners]cornerSlot{
+	cURF: {vec{1, 1, 1}, [3]Axis{Zax, Xax, Yax}},
+	cUFL: {vec{-1, 1, 1}, [3]Axis{Zax, Yax, Xax}},
+	cULB: {vec{-1, -1, 1}, [3]Axis{Zax, Xax, Yax}},
+	cUBR: {vec{1, -1, 1}, [3]Axis{Zax, Yax, Xax}},
+	cDFR: {vec{1, 1, -1}, [3]Axis{Zax, Yax, Xax}},
+	cDLF: {vec{-1, 1, -1}, [3]Axis{Zax, Xax, Yax}},
+	cDBL: {vec{-1, -1, -1}, [3]Axis{Zax, Yax, Xax}},
+	cDRB: {vec{1, -1, -1}, [3]Axis{Zax, Xax, Yax}},
+}
+
+// The colors a corner slot has when the cube is solved
+func (f faceColors) corner(slot int) [3]Color {
+	var ret [3]Color
+	for k, ax := range cornerSlots[slot].axes {
+		ret[k] = f[ax][side(cornerSlots[slot].pos[ax])]
+	}
+	return ret
+}
+
+// The piece in each corner slot and its twist.  A twist of 0 means the
+// piece's U or D sticker is on the slot's U or D face, 1 and 2 mean it is
+// on the next face clockwise, and the one after that.
+type cornerCubies struct {
+	p [nCorners]uint8
+	o [nCorners]uint8
+}
+
+var solvedCorners = cornerCubies{p: [nCorners]uint8{0, 1, 2, 3, 4, 5, 6, 7}}
+
+// Edge slots, in Kociemba's order
+const (
+	eUR = iota
+	eUF
+	eUL
+	eUB
+	eDR
+	eDF
+	eDL
+	eDB
+	eFR
+	eFL
+	eBL
+	eBR
+	nEdges
+)
+
+type edgeSlot struct {
+	pos  vec     // the signs of the slot's coordinates, 0 along its length
+	axes [2]Axis // the slot's faces, starting from U or D, or from F or B
+}
+
+var edgeSlots = [nEdges]edgeSlot{
+	eUR: {vec{1, 0, 1}, [2]Axis{Zax, Xax}},
+	eUF: {vec{0, 1, 1}, [2]Axis{Zax, Yax}},
+	eUL: {vec{-1, 0, 1}, [2]Axis{Zax, Xax}},
+	eUB: {vec{0, -1, 1}, [2]Axis{Zax, Yax}},
+	eDR: {vec{1, 0, -1}, [2]Axis{Zax, Xax}},
+	eDF: {vec{0, 1, -1}, [2]Axis{Zax, Yax}},
+	eDL: {vec{-1, 0, -1}, [2]Axis{Zax, Xax}},
+	eDB: {vec{0, -1, -1}, [2]Axis{Zax, Yax}},
+	eFR: {vec{1, 1, 0}, [2]Axis{Yax, Xax}},
+	eFL: {vec{-1, 1, 0}, [2]Axis{Yax, Xax}},
+	eBL: {vec{-1, -1, 0}, [2]Axis{Yax, Xax}},
+	eBR: {vec{1, -1, 0}, [2]Axis{Yax, Xax}},
+}
+
+// The colors an edge slot has when the cube is solved
+func (f faceColors) edge(slot int) [2]Color {
+	var ret [2]Color
+	for k, ax := range edgeSlots[slot].axes {
+		ret[k] = f[ax][side(edgeSlots[slot].pos[ax])]
+	}
+	return ret
+}
+
+// The piece in each edge slot and whether it is flipped
+type edgeCubies struct {
+	p [nEdges]uint8
+	o [nEdges]uint8
+}
+
+var solvedEdges = edgeCubies{p: [nEdges]uint8{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}}
+
+// Performs b after a
+func (a edgeCubies) mult(b edgeCubies) edgeCubies {
+	var ret edgeCubies
+	for i := range nEdges {
+		ret.p[i] = a.p[b.p[i]]
+		ret.o[i] = (a.o[b.p[i]] + b.o[i]) % 2
+	}
+	return ret
+}
+
+func (a edgeCubies) inverse() edgeCubies {
+	var ret edgeCubies
+	for i := range nEdges {
+		ret.p[a.p[i]] = uint8(i)
+		ret.o[a.p[i]] = a.o[i]
+	}
+	return ret
+}
+
+// The corners and edges of a 3x3x3 cube
+type cubieCube struct {
+	corners cornerCubies
+	edges   edgeCubies
+}
+
+var solvedCubies = cubieCube{solvedCorners, solvedEdges}
+
+// Performs b after a
+func (a cubieCube) mult(b cubieCube) cubieCube {
+	return cubieCube{a.corners.mult(b.corners), a.edges.mult(b.edges)}
+}
+
+func (a cubieCube) inverse() cubieCube {
+	return cubieCube{a.corners.inverse(), a.edges.inverse()}
+}
+
+// Reports whether the cubies can be reached by turning faces: corners
+// twisted and edges flipped as a whole, and permutations of corners and
+// edges of the same parity
+func (a cubieCube) solvable() bool {
+	twist, flip := 0, 0
+	for _, o := range a.corners.o {
+		twist += int(o)
+	}
+	for _, o := range a.edges.o {
+		flip += int(o)
+	}
+	return twist%3 == 0 && flip%2 == 0 && permParity(a.corners.p[:]) == permParity(a.edges.p[:])
+}
+
+// Performs b after a
+func (a cornerCubies) mult(b cornerCubies) cornerCubies {
+	var ret cornerCubies
+	for i := range nCorners {
+		ret.p[i] = a.p[b.p[i]]
+		ret.o[i] = (a.o[b.p[i]] + b.o[i]) % 3
+	}
+	return ret
+}
+
+func (a cornerCubies) inverse() cornerCubies {
+	var ret cornerCubies
+	for i := range nCorners {
+		ret.p[a.p[i]] = uint8(i)
+		ret.o[a.p[i]] = (3 - a.o[i]) % 3
+	}
+	return ret
+}
+
+// Returns the faces of a cube as given by its centers.
+// Only cubes of odd size have centers.
+func centerFaces(cube Cube) faceColors {
+	var f faceColors
+	h := int(cube.n / 2)
+	for _, cbi := range cube.cubis {
+		for _, ax := range [...]Axis{Xax, Yax, Zax} {
+			var center vec
+			center[ax] = cbi.pv[ax]
+			if (cbi.pv[ax] == h || cbi.pv[ax] == -h) && cbi.pv == center {
+				f[ax][side(cbi.pv[ax])] = cbi.cv[ax].Abs()
+			}
+		}
+	}
+	return f
+}
+
+// Returns the faces of a cube as seen from the corner at DBL.  The colors
+// of U, F and R are the ones that never share a corner with D, B and L.
+func cornerFaces(cube Cube) (faceColors, bool) {
+	var f faceColors
+	h := int(cube.n / 2)
+	var together [7][7]bool
+	for _, cbi := range cube.cubis {
+		if cbi.pv[Xax]*cbi.pv[Xax] != h*h || cbi.pv[Yax]*cbi.pv[Yax] != h*h || cbi.pv[Zax]*cbi.pv[Zax] != h*h {
+			continue
+		}
+		for _, a := range cbi.cv {
+			for _, b := range cbi.cv {
+				together[a.Abs()][b.Abs()] = true
+			}
+		}
+		if cbi.pv == (vec{-h, -h, -h}) {
+			for ax := range f {
+				f[ax][0] = cbi.cv[ax].Abs()
+			}
+		}
+	}
+	for ax := range f {
+		for c := Green; c <= Blue; c++ {
+			if !together[f[ax][0]][c] {
+				if f[ax][1] != zero {
+					return f, false
+				}
+				f[ax][1] = c
+			}
+		}
+		if f[ax][1] == zero {
+			return f, false
+		}
+	}
+	return f, true
+}
+
+// Reads the corners of a cube.  Returns false if a corner cannot be found
+// on a solved cube with the given faces, or if a corner appears twice.
+func toCornerCubies(cube Cube, f faceColors) (cornerCubies, bool) {
+	var ret cornerCubies
+	h := int(cube.n / 2)
+	var seen [nCorners]bool
+	for _, cbi := range cube.cubis {
+		slot := -1
+		for i, cs := range cornerSlots {
+			if cbi.pv == (vec{cs.pos[0] * h, cs.pos[1] * h, cs.pos[2] * h}) {
+				slot = i
+			}
+		}
+		if slot < 0 {
+			continue
+		}
+		var colors [3]Color
+		for k, ax := range cornerSlots[slot].axes {
+			colors[k] = cbi.cv[ax].Abs()
+		}
+		found := false
+		for piece := range nCorners {
+			home := f.corner(piece)
+			for twist := range 3 {
+				if colors[twist] == home[0] && colors[(twist+1)%3] == home[1] && colors[(twist+2)%3] == home[2] {
+					if seen[piece] {
+						return ret, false
+					}
+					seen[piece] = true
+					ret.p[slot] = uint8(piece)
+					ret.o[slot] = uint8(twist)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return ret, false
+		}
+	}
+	return ret, true
+}
+
+// Reads the edges in the middle of the edges of a cube of odd size.
+// Returns false if an edge cannot be found on a solved cube with the given
+// faces, if an edge appears twice or if a slot is empty.
+func toEdgeCubies(cube Cube, f faceColors) (edgeCubies, bool) {
+	var ret edgeCubies
+	h := int(cube.n / 2)
+	var seen [nEdges]bool
+	filled := 0
+	for _, cbi := range cube.cubis {
+		slot := -1
+		for i, es := range edgeSlots {
+			if cbi.pv == (vec{es.pos[0] * h, es.pos[1] * h, es.pos[2] * h}) {
+				slot = i
+			}
+		}
+		if slot < 0 {
+			continue
+		}
+		var colors [2]Color
+		for k, ax := range edgeSlots[slot].axes {
+			colors[k] = cbi.cv[ax].Abs()
+		}
+		found := false
+		for piece := range nEdges {
+			home := f.edge(piece)
+			for flip := range 2 {
+				if colors[flip] == home[0] && colors[1-flip] == home[1] {
+					if seen[piece] {
+						return ret, false
+					}
+					seen[piece] = true
+					ret.p[slot] = uint8(piece)
+					ret.o[slot] = uint8(flip)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return ret, false
+		}
+		filled++
+	}
+	return ret, filled == nEdges
+}
+
+// Reads the corners and edges of a 3x3x3 cube
+func toCubieCube(cube Cube, f faceColors) (cubieCube, bool) {
+	corners, ok := toCornerCubies(cube, f)
+	if !ok {
+		return cubieCube{}, false
+	}
+	edges, ok := toEdgeCubies(cube, f)
+	return cubieCube{corners, edges}, ok
+}
+
+// Reads a 3x3x3 start cube relative to an end cube: the result is solved
+// when the start cube is equal to the end cube, up to a rotation.  Also
+// returns the end cube, rotated so that its centers match the start
+// cube's.  Returns false if the cubes are not of size three or if there is
+// no path between them.
+func toRelativeCubies(start Cube, end Cube) (cubieCube, Cube, bool) {
+	if start.n != 3 || end.n != 3 {
+		return cubieCube{}, end, false
+	}
+	f := centerFaces(start)
+	for _, rotated := range end.GetAllRotations() {
+		if centerFaces(rotated) != f {
+			continue
+		}
+		s, ok := toCubieCube(start, f)
+		if !ok {
+			return cubieCube{}, end, false
+		}
+		e, ok := toCubieCube(rotated, f)
+		if !ok {
+			return cubieCube{}, end, false
+		}
+		c := e.inverse().mult(s)
+		return c, rotated, c.solvable()
+	}
+	return cubieCube{}, end, false
+}
+
+// Returns the corners of a move performed on a solved cube of size n
+func moveCorners(n uint, m Move) cornerCubies {
+	solved := New(n)
+	f, _ := cornerFaces(solved)
+	if n%2 == 1 {
+		f = centerFaces(solved)
+	}
+	ret, _ := toCornerCubies(solved.Move(m), f)
+	return ret
+}
+
+// Returns the cubies of a move performed on a solved 3x3x3 cube
+func moveCubies(m Move) cubieCube {
+	solved := New(3)
+	ret, _ := toCubieCube(solved.Move(m), centerFaces(solved))
+	return ret
+}
+
+// A face turned clockwise once, twice or three times
+type faceTurn struct {
+	face  Move
+	times int
+}
+
+// The quarter turns that make up a face turn.  Half turns are two
+// clockwise quarter turns.
+func (ft faceTurn) moves() []Move {
+	if ft.times == 3 {
+		return []Move{{Axis: ft.face.Axis, Idx: ft.face.Idx, Direction: !ft.face.Direction}}
+	}
+	ret := make([]Move, ft.times)
+	for i := range ret {
+		ret[i] = ft.face
+	}
+	return ret
+}
+
+// Lehmer code of a permutation of 0..len(p)-1
+func permRank(p []uint8) int {
+	ret := 0
+	for i := range p {
+		smaller := 0
+		for j := i + 1; j < len(p); j++ {
+			if p[j] < p[i] {
+				smaller++
+			}
+		}
+		ret = ret*(len(p)-i) + smaller
+	}
+	return ret
+}
+
+// The inverse of permRank
+func permUnrank(n int, rank int) []uint8 {
+	code := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		code[i] = rank % (n - i)
+		rank /= n - i
+	}
+	ret := make([]uint8, n)
+	used := make([]bool, n)
+	for i, c := range code {
+		for v := range n {
+			if used[v] {
+				continue
+			}
+			if c == 0 {
+				ret[i] = uint8(v)
+				used[v] = true
+				break
+			}
+			c--
+		}
+	}
+	return ret
+}
+
+// 0 for even permutations, 1 for odd ones
+func permParity(p []uint8) int {
+	ret := 0
+	for i := range p {
+		for j := i + 1; j < len(p); j++ {
+			if p[j] < p[i] {
+				ret ^= 1
+			}
+		}
+	}
+	return ret
+}
+
+// Number of ways of choosing k out of n
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	ret := 1
+	for i := 0; i < k; i++ {
+		ret = ret * (n - i) / (i + 1)
+	}
+	return ret
+}
@@ -0,0 +1,375 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Cuboid is a puzzle of x by y by z, such as the 2x2x3 or the 3x3x4, made
+// of cubis like a Cube.  The zero value is not usable, cuboids are created
+// with NewCuboid.
+//
+// A layer turns by 90 degrees only if the cuboid is as long along the two
+// axes the layer spans, otherwise the layer would not fit back in place,
+// and turns by 180 degrees instead.  On a 2x2x3, layers along z turn by 90
+// degrees and layers along x and y by 180.
+//
+// A cuboid can be a single cubi thick along an axis, such as the 1x2x3.
+// Cubis then have a sticker on each side of that axis, but room for one
+// color in their color vector.  The color vector holds the color facing
+// the positive side, and the negative side has the opposite color in the
+// color scheme.  Turns move the stickers along, since they turn the cubi
+// as a whole, and the color left facing the positive side is then kept.
+type Cuboid struct {
+	dims     [3]uint
+	opposite [Blue + 1]Color // the color across a thin cubi from each color
+	cubis    []cubi
+}
+
+// NewCuboid returns a solved cuboid of x by y by z, in the Western color
+// scheme.  It panics if a size is zero.
+func NewCuboid(x, y, z uint) Cuboid {
+	return Western().NewCuboid(x, y, z)
+}
+
+// NewCuboid returns a solved cuboid of x by y by z colored with the
+// scheme.  It panics if a size is zero or if the faces do not have each
+// color once.
+func (s ColorScheme) NewCuboid(x, y, z uint) Cuboid {
+	if x == 0 || y == 0 || z == 0 {
+		panic("sizes must be greater than 0")
+	}
+	if !s.valid() {
+		panic(fmt.Sprintf("invalid color scheme %v", s.Faces))
+	}
+	dims := [3]uint{x, y, z}
+	f := s.faceColors()
+	ret := Cuboid{dims: dims}
+	for ax := range f {
+		ret.opposite[f[ax][0]] = f[ax][1]
+		ret.opposite[f[ax][1]] = f[ax][0]
+	}
+	ret.cubis = make([]cubi, 0, len(getCuboidIndex(dims).cubis))
+	for _, pv := range getCuboidIndex(dims).cubis {
+		var cv cVec
+		for ax, p := range pv {
+			h := int(dims[ax] / 2)
+			if p == h {
+				cv[ax] = f[ax][1]
+			} else if p == -h {
+				cv[ax] = -f[ax][0]
+			}
+		}
+		ret.cubis = append(ret.cubis, cubi{cv: cv, pv: pv})
+	}
+	return ret
+}
+
+// Where the cubis of a cuboid are kept: by x, then y, then z, leaving out
+// the positions inside the cuboid
+type cuboidIndex struct {
+	cubis []vec       // the position of each cubi
+	index map[vec]int // the index of each position
+}
+
+var cuboidIndexes sync.Map // sizes to *cuboidIndex
+
+func getCuboidIndex(dims [3]uint) *cuboidIndex {
+	if t, ok := cuboidIndexes.Load(dims); ok {
+		return t.(*cuboidIndex)
+	}
+	t := &cuboidIndex{index: make(map[vec]int)}
+	for i := range int(dims[Xax]) {
+		for j := range int(dims[Yax]) {
+			for k := range int(dims[Zax]) {
+				pv := vec{coordValue(dims[Xax], i), coordValue(dims[Yax], j), coordValue(dims[Zax], k)}
+				inside := true
+				for ax, p := range pv {
+					h := int(dims[ax] / 2)
+					if p == h || p == -h {
+						inside = false
+					}
+				}
+				if inside {
+					continue
+				}
+				t.index[pv] = len(t.cubis)
+				t.cubis = append(t.cubis, pv)
+			}
+		}
+	}
+	actual, _ := cuboidIndexes.LoadOrStore(dims, t)
+	return actual.(*cuboidIndex)
+}
+
+// Size returns the size of the cuboid along x, y and z
+func (c Cuboid) Size() (x, y, z uint) {
+	return c.dims[Xax], c.dims[Yax], c.dims[Zax]
+}
+
+// Copy returns a cuboid that shares no cubis with c
+func (c Cuboid) Copy() Cuboid {
+	ret := c
+	ret.cubis = append([]cubi(nil), c.cubis...)
+	return ret
+}
+
+// Equal tells whether two cuboids have the same size and colors
+func (c Cuboid) Equal(other Cuboid) bool {
+	if c.dims != other.dims || c.opposite != other.opposite || len(c.cubis) != len(other.cubis) {
+		return false
+	}
+	for i := range c.cubis {
+		if c.cubis[i] != other.cubis[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// QuarterTurns tells whether the layers along an axis turn by 90 degrees
+func (c Cuboid) QuarterTurns(ax Axis) bool {
+	return c.dims[(ax+1)%3] == c.dims[(ax+2)%3]
+}
+
+// Move turns the layer at index Idx along Axis, by 90 degrees in Direction
+// if the layers along Axis allow it, and by 180 degrees otherwise.  Valid
+// indices range from -d/2 to d/2, where d is the size along Axis,
+// excluding 0 when d is even.  Other moves leave the cuboid as it is.
+func (c Cuboid) Move(m Move) Cuboid {
+	d := c.dims[m.Axis]
+	h := int(d / 2)
+	if m.Idx < -h || m.Idx > h || (d%2 == 0 && m.Idx == 0) {
+		return c
+	}
+	mat := getRotationMatrix(m.Axis, m.Direction)
+	index := getCuboidIndex(c.dims).index
+	ret := c.Copy()
+	for _, cbi := range c.cubis {
+		if cbi.pv[m.Axis] != m.Idx {
+			continue
+		}
+		cbi = mat.mult(cbi)
+		if !c.QuarterTurns(m.Axis) {
+			cbi = mat.mult(cbi)
+		}
+		for ax, d := range c.dims {
+			if d == 1 && cbi.cv[ax] < 0 {
+				cbi.cv[ax] = c.opposite[-cbi.cv[ax]]
+			}
+		}
+		ret.cubis[index[cbi.pv]] = cbi
+	}
+	return ret
+}
+
+// MoveAll performs the moves in order and returns the final cuboid
+func (c Cuboid) MoveAll(ms []Move) Cuboid {
+	for _, m := range ms {
+		c = c.Move(m)
+	}
+	return c
+}
+
+// LegalMoves returns the moves that turn a layer of the cuboid, each
+// once: both directions of the layers that turn by 90 degrees, and one of
+// those that turn by 180, whose directions are the same move
+func (c Cuboid) LegalMoves() []Move {
+	var ret []Move
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		for i := range int(c.dims[ax]) {
+			idx := coordValue(c.dims[ax], i)
+			ret = append(ret, Move{Axis: ax, Idx: idx, Direction: Clock})
+			if c.QuarterTurns(ax) {
+				ret = append(ret, Move{Axis: ax, Idx: idx, Direction: Counterclock})
+			}
+		}
+	}
+	return ret
+}
+
+// Shuffle performs a number of moves picked at random among LegalMoves
+func (c *Cuboid) Shuffle(times uint) {
+	ms := c.LegalMoves()
+	for range times {
+		*c = c.Move(ms[rand.Intn(len(ms))])
+	}
+}
+
+// The color of the sticker on a row and column of a face, in the order of
+// faceletFaces
+func (c Cuboid) sticker(face, r, col int) Color {
+	pv, ax := cuboidPlace(c.dims, face, r, col)
+	color := c.cubis[getCuboidIndex(c.dims).index[pv]].cv[ax]
+	if c.dims[ax] > 1 {
+		return color.Abs()
+	}
+	// A thin cubi shows its color on the positive side, one of U, R and F,
+	// and the opposite on the other
+	if face < 3 {
+		return color
+	}
+	return c.opposite[color]
+}
+
+// The number of rows and columns of a face, and where it is in the Flat
+func (c Cuboid) faceLayout(face int) (rows, cols, r0, c0 int) {
+	x, y, z := int(c.dims[Xax]), int(c.dims[Yax]), int(c.dims[Zax])
+	switch faceletFaces[face] {
+	case 'U':
+		return y, x, 0, y
+	case 'R':
+		return z, y, y, y + x
+	case 'F':
+		return z, x, y, y
+	case 'D':
+		return y, x, y + z, y
+	case 'L':
+		return z, y, y, 0
+	default: // 'B'
+		return z, x, y, 2*y + x
+	}
+}
+
+// IsSolved tells whether every side of the cuboid has a single color
+func (c Cuboid) IsSolved() bool {
+	for face := range faceletFaces {
+		rows, cols, _, _ := c.faceLayout(face)
+		first := c.sticker(face, 0, 0)
+		for r := range rows {
+			for col := range cols {
+				if c.sticker(face, r, col) != first {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// PaintCuboid overwrites the Flat with a picture of the cuboid.  The sides
+// are laid out as for a cube, each as wide and high as the cuboid along
+// the axes it spans, so the Flat of a cuboid of x by y by z has 2y+z rows
+// of 2x+2y stickers.  Flat.String tells the sides apart from the stickers
+// painted, so it prints the Flat as Cuboid.String prints the cuboid, and
+// Flat.Cuboid reads it back.
+func (fl *Flat) PaintCuboid(c Cuboid) {
+	x, y, z := c.Size()
+	*fl = make([][]string, 2*y+z)
+	for idx := range *fl {
+		(*fl)[idx] = make([]string, 2*x+2*y)
+	}
+	for face := range faceletFaces {
+		rows, cols, r0, c0 := c.faceLayout(face)
+		for r := range rows {
+			for col := range cols {
+				(*fl)[r0+r][c0+col] = c.sticker(face, r, col).String()
+			}
+		}
+	}
+}
+
+// Cuboid reconstructs a cuboid from the Flat PaintCuboid draws.  It fails
+// if the stickers are not the sides of a cuboid, or if a sticker is not a
+// color.
+//
+// The color across a thin cubi from each color is that of the cubi's
+// other side.  On cuboids with no thin cubis, it is the color never seen
+// next to it on a cubi, the one on the opposite side.
+func (fl Flat) Cuboid() (Cuboid, error) {
+	x, y, z := fl.layout()
+	if x < 1 || !fl.fills(x, y, z) || len(fl) != 2*y+z {
+		return Cuboid{}, fmt.Errorf("flat: not the net of a cuboid")
+	}
+	ret := NewCuboid(uint(x), uint(y), uint(z))
+	index := getCuboidIndex(ret.dims).index
+	colors := make([][3][2]Color, len(ret.cubis)) // per axis, negative side first
+	for face := range faceletFaces {
+		rows, cols, r0, c0 := ret.faceLayout(face)
+		for r := range rows {
+			for c := range cols {
+				s := fl[r0+r][c0+c]
+				color, err := ParseColor(s)
+				if err != nil || color == zero {
+					return Cuboid{}, fmt.Errorf("flat: unknown color %q at row %d, column %d", s, r0+r, c0+c)
+				}
+				pv, ax := cuboidPlace(ret.dims, face, r, c)
+				side := 0
+				if face < 3 {
+					side = 1
+				}
+				colors[index[pv]][ax][side] = color
+			}
+		}
+	}
+
+	// Colors seen together on a cubi are not opposite
+	var together [Blue + 1][Blue + 1]bool
+	var opposite [Blue + 1]Color
+	for _, cs := range colors {
+		for ax, sides := range cs {
+			if sides[0] != zero && sides[1] != zero {
+				opposite[sides[0]], opposite[sides[1]] = sides[1], sides[0]
+			}
+			for _, c := range sides {
+				for other, others := range cs {
+					for _, d := range others {
+						if other != ax && c != zero && d != zero {
+							together[c][d] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	for c := Green; c <= Blue; c++ {
+		if opposite[c] != zero {
+			continue
+		}
+		var candidates []Color
+		for d := Green; d <= Blue; d++ {
+			if d != c && !together[c][d] {
+				candidates = append(candidates, d)
+			}
+		}
+		if len(candidates) == 1 {
+			opposite[c] = candidates[0]
+		} else {
+			opposite[c] = ret.opposite[c]
+		}
+	}
+	ret.opposite = opposite
+
+	for i, cs := range colors {
+		var cv cVec
+		for ax, sides := range cs {
+			switch {
+			case sides[1] != zero:
+				cv[ax] = sides[1]
+			case sides[0] != zero:
+				cv[ax] = -sides[0]
+			}
+		}
+		ret.cubis[i].cv = cv
+	}
+	return ret, nil
+}
+
+// String prints the cuboid opened up as a Flat
+func (c Cuboid) String() string {
+	var fl Flat
+	fl.PaintCuboid(c)
+	return fl.text(int(c.dims[Xax]), int(c.dims[Yax]), int(c.dims[Zax]), func(s string) string { return s })
+}
+
+// Text prints the cuboid opened up as a Flat, in color.  See Flat.Text.
+func (c Cuboid) Text(opts TextOptions) string {
+	var fl Flat
+	fl.PaintCuboid(c)
+	return fl.colorText(int(c.dims[Xax]), int(c.dims[Yax]), int(c.dims[Zax]), opts)
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cube implements Rubik's cubes of size n x n x n for n larger
+// than one.
+//
+// A Cube is a list of cubis, the little cubes on the outside of the
+// puzzle.  Each cubi has a position vector and a color vector.  The
+// coordinate system is
+//
+//	    z
+//	    |
+//	    |
+//	    ._________ x
+//	   /
+//	  /
+//	 /
+//	y
+//
+// and, for a cube of size n, positions range from -n/2 to n/2 on every
+// axis.  On cubes of even size there is no layer at index 0.
+//
+// The following invariants hold for every Cube returned by this package:
+//
+//   - a cube of size n has n^3 - (n-2)^3 cubis;
+//   - a cubi has a non-zero color on an axis if and only if it sits on
+//     that axis's extremity, that is, if the sticker is visible;
+//   - the sign of a color matches the sign of the position on the same
+//     axis, so a sticker facing x<0 has a negative color;
+//   - cubis are stored in the order of their positions, so cubes that
+//     look the same hold the same cubis, and a move only visits the
+//     cubis of the layer it turns.
+//
+// A Cube is a value: Move and Rotate return a new cube and leave the
+// receiver untouched.  Apply and ApplyAll perform moves in place, without
+// allocating, for code that performs many of them.
+//
+// The package is safe for concurrent use.  It keeps no settings in global
+// variables: renderers such as Text, Isometric and WriteSVG take their
+// colors in their options, and the tables built on first use are guarded.
+// Any number of goroutines may call the methods of a cube that take it by
+// value, such as Move, String or Validate, while no goroutine changes it.
+// Apply, Shuffle and the other methods on *Cube change the cube in place,
+// and assigning a cube to another shares the cubis, so a goroutine that
+// changes a cube shared with others should Copy it first.
+//
+// Moves turn a single layer by 90 degrees.  A Move is identified by the
+// Axis it turns about, the index of the layer along that axis, and a
+// Direction, which is clockwise or counterclockwise when looking at the
+// layer from the positive end of the axis.
+//
+// Flat opens a cube up into two dimensions, which is used for printing
+// and for loading cubes from text.  A Solver finds a list of moves that
+// takes one cube to another.
+//
+// A Cuboid is a puzzle of x by y by z made of cubis in the same way, where
+// layers that would not fit back after a quarter turn turn by 180 degrees.
+package cube
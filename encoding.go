@@ -0,0 +1,129 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+)
+
+// The binary encoding of a cube is its size, as a varint, followed by the
+// colors of its 6*n*n stickers, three bits each, packed from the least
+// significant bit of each byte.  The stickers are ordered by face, R, L,
+// F, B, U then D, as in the axes and their positive then negative side,
+// then by the coordinates of the other two axes, from negative to
+// positive.  Which side a sticker faces is given by its face, so only the
+// absolute value of its color is stored.
+//
+// The encoding only depends on the colors of the faces, not on the order
+// of the pieces of the cube, so cubes that look the same have the same
+// encoding.
+
+const stickerBits = 3
+
+// The index of the sticker on the side of a position facing along an axis
+func stickerIndex(n uint, pv vec, ax Axis) int {
+	face := 2 * int(ax)
+	if pv[ax] < 0 {
+		face++
+	}
+	u, v := (ax+1)%3, (ax+2)%3
+	return (face*int(n)+coordIndex(n, pv[u]))*int(n) + coordIndex(n, pv[v])
+}
+
+// Appends the binary encoding of a cube to a slice
+func (cube Cube) appendBinary(buf []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(cube.n))
+	start := len(buf)
+	buf = append(buf, make([]byte, (6*int(cube.n)*int(cube.n)*stickerBits+7)/8)...)
+	stickers := buf[start:]
+	h := int(cube.n / 2)
+	for _, cbi := range cube.cubis {
+		for ax, x := range cbi.pv {
+			if x != h && x != -h {
+				continue
+			}
+			bit := stickerIndex(cube.n, cbi.pv, Axis(ax)) * stickerBits
+			c := uint16(cbi.cv[ax].Abs()) << (bit % 8)
+			stickers[bit/8] |= byte(c)
+			if c > 0xff {
+				stickers[bit/8+1] |= byte(c >> 8)
+			}
+		}
+	}
+	return buf
+}
+
+// MarshalBinary encodes a cube in a compact form that is the same for
+// all cubes that look the same
+func (cube Cube) MarshalBinary() ([]byte, error) {
+	return cube.appendBinary(nil), nil
+}
+
+// UnmarshalBinary decodes a cube encoded by MarshalBinary
+func (cube *Cube) UnmarshalBinary(data []byte) error {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n < 2 || n > 1<<16 {
+		return errors.New("cube: invalid size in binary encoding")
+	}
+	stickers := data[k:]
+	bits := 6 * int(n) * int(n) * stickerBits
+	if len(stickers) != (bits+7)/8 {
+		return errors.New("cube: invalid length of binary encoding")
+	}
+	// Only visible stickers are encoded, so nothing follows the last one
+	if bits%8 != 0 && stickers[len(stickers)-1]>>(bits%8) != 0 {
+		return errors.New("cube: invalid padding in binary encoding")
+	}
+	ret := New(uint(n))
+	h := int(n / 2)
+	for i, cbi := range ret.cubis {
+		for ax, x := range cbi.pv {
+			if x != h && x != -h {
+				continue
+			}
+			bit := stickerIndex(ret.n, cbi.pv, Axis(ax)) * stickerBits
+			c := uint16(stickers[bit/8])
+			if bit/8+1 < len(stickers) {
+				c |= uint16(stickers[bit/8+1]) << 8
+			}
+			color := Color(c>>(bit%8)) & (1<<stickerBits - 1)
+			if color == zero || color > Blue {
+				return errors.New("cube: invalid color in binary encoding")
+			}
+			if x < 0 {
+				color = -color
+			}
+			ret.cubis[i].cv[ax] = color
+		}
+	}
+	*cube = ret
+	return nil
+}
+
+// Key returns the binary encoding of a cube as a string, to be used as a
+// map key.  Cubes that look the same have the same key.
+func (cube Cube) Key() string {
+	var buf [64]byte
+	return string(cube.appendBinary(buf[:0]))
+}
+
+// Equal tells whether two cubes look the same, in the same orientation
+func (cube Cube) Equal(other Cube) bool {
+	return cube.n == other.n && slices.Equal(cube.cubis, other.cubis)
+}
+
+// Hash returns the 64-bit FNV-1a hash of the binary encoding of a cube.
+// Cubes that look the same have the same hash.
+func (cube Cube) Hash() uint64 {
+	var buf [64]byte
+	h := uint64(14695981039346656037)
+	for _, b := range cube.appendBinary(buf[:0]) {
+		h ^= uint64(b)
+		h *= 1099511628211
+	}
+	return h
+}
@@ -7,7 +7,7 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
 // The commutator of g and h is [g,h]:
@@ -23,35 +23,27 @@ import (
 // We pick some turns to represent g and h and then we compute
 // g.h as well as [g,h]
 // Note that [g,h] is closer to the identify than g.h
+//
+// Cubers write commutators the other way around, [A, B] = A B A' B',
+// which is what cube.Commutator computes, so [g,h] above is
+// cube.Commutator(g', h').
 func main() {
-	internal.PrintInColors(false)
-	cb := internal.New(3)
+	cb := cube.New(3)
 	fmt.Println(cb)
 	fmt.Println()
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock}) // g
-	cb = cb.Move(internal.Move{Axis: internal.Yax, Idx: -1, Direction: internal.Counterclock}) // h
-	fmt.Println("g . h")
+	g := cube.Algorithm{{Axis: cube.Xax, Idx: -1, Direction: cube.Counterclock}}
+	h := cube.Algorithm{{Axis: cube.Yax, Idx: -1, Direction: cube.Counterclock}}
+
+	cb = cb.MoveAll(g.Concat(h))
+	fmt.Println("g . h =", g.Concat(h).Format(3))
 	fmt.Println(cb)
 	fmt.Println()
 
 	cb.Reset()
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Clock}) // g^(-1)
-	//fmt.Println(cb)
-	//fmt.Println()
-	cb = cb.Move(internal.Move{Axis: internal.Yax, Idx: -1, Direction: internal.Clock}) // h^(-1)
-	//fmt.Println("g^(-1) . h^(-1)")
-	//fmt.Println(cb)
-	//fmt.Println()
-
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock}) // g
-	//fmt.Println(cb)
-	//fmt.Println()
-	cb = cb.Move(internal.Move{Axis: internal.Yax, Idx: -1, Direction: internal.Counterclock}) // h
-	fmt.Println("g^(-1) . h^(-1) . g . h")
+	cb = cb.MoveAll(cube.Commutator(g.Inverse(), h.Inverse()))
+	fmt.Println("g^(-1) . h^(-1) . g . h =", cube.Commutator(g.Inverse(), h.Inverse()).Format(3))
 	fmt.Println(cb)
 	fmt.Println()
-
-	return
 }
@@ -7,21 +7,20 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
 func main() {
-	internal.PrintInColors(false)
 	fmt.Println("Printing all possible moves of a Rubik's cube,")
 	fmt.Println("starting from the initial configuration.")
 	fmt.Println()
 	var n uint
 	n = 3
-	cb := internal.New(n)
-	var c2 internal.Cube
-	for _, ax := range [...]internal.Axis{internal.Xax, internal.Yax, internal.Zax} {
+	cb := cube.New(n)
+	var c2 cube.Cube
+	for _, ax := range [...]cube.Axis{cube.Xax, cube.Yax, cube.Zax} {
 		for idx := -int(n) / 2; idx <= int(n)/2; idx++ {
-			for _, dir := range [...]internal.Direction{internal.Counterclock, internal.Clock} {
+			for _, dir := range [...]cube.Direction{cube.Counterclock, cube.Clock} {
 				if n%2 == 0 && idx == 0 {
 					continue
 				}
@@ -29,7 +28,7 @@ func main() {
 				fmt.Printf("at index %d in the direction %s\n", idx, dir)
 				fmt.Println(cb)
 				fmt.Println()
-				c2 = cb.Move(internal.Move{Axis: ax, Idx: idx, Direction: dir})
+				c2 = cb.Move(cube.Move{Axis: ax, Idx: idx, Direction: dir})
 				fmt.Println(c2)
 				fmt.Println()
 			}
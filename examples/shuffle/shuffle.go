@@ -7,13 +7,12 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
 // Perform moves at random, starting from the initial configuration
 func main() {
-	internal.PrintInColors(false)
-	cb := internal.New(3)
+	cb := cube.New(3)
 	fmt.Print("shuffle: performing moves at random, ")
 	fmt.Println("starting from the initial configuration")
 	fmt.Println(cb)
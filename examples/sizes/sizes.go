@@ -7,22 +7,21 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
 func main() {
-	internal.PrintInColors(false)
 	fmt.Println("Cubes of different sizes")
-	cb := internal.New(2)
+	cb := cube.New(2)
 	fmt.Println(cb)
 	fmt.Println()
-	cb = internal.New(3)
+	cb = cube.New(3)
 	fmt.Println(cb)
 	fmt.Println()
-	cb = internal.New(4)
+	cb = cube.New(4)
 	fmt.Println(cb)
 	fmt.Println()
-	cb = internal.New(5)
+	cb = cube.New(5)
 	fmt.Println(cb)
 	fmt.Println()
 }
@@ -7,65 +7,64 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
-func swap_top_corners(cb internal.Cube) internal.Cube {
+func swap_top_corners(cb cube.Cube) cube.Cube {
 	// Swap cubi (1,1,1) with cubi (-1,1,1)
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Clock})
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: -1, Direction: cube.Clock})
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Clock})
 
 	//fmt.Println(cb)
 	//fmt.Println()
 
 	//
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Counterclock})
 	//fmt.Println(cb)
 	//fmt.Println()
 
 	// Reverse swap
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Clock})
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: -1, Direction: cube.Clock})
 
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 1, Direction: cube.Clock})
 
 	//fmt.Println(cb)
 	//fmt.Println()
 
 	//
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock})
 	return cb
 }
 
 func main() {
-	internal.PrintInColors(false)
-	cb := internal.New(3)
+	cb := cube.New(3)
 	fmt.Println(cb)
 	fmt.Println()
 
 	cb = swap_top_corners(cb)
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock})
 
 	cb = swap_top_corners(cb)
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock}) //cb = cb.Turn(Zax, 1, Counterclock)
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock}) //cb = cb.Turn(Zax, 1, Counterclock)
 
 	fmt.Println(cb)
 	fmt.Println()
@@ -7,57 +7,25 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
-func swap_top_corners(cb internal.Cube) internal.Cube {
+func swap_top_corners(cb cube.Cube) cube.Cube {
 	// Swap cubi (1,1,1) with cubi (-1,1,1)
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
-
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Clock})
-
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
-
-	//fmt.Println(cb)
-	//fmt.Println()
-
-	//
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
-	//fmt.Println(cb)
-	//fmt.Println()
-
-	// Reverse swap
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
-
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: -1, Direction: internal.Clock})
-
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 1, Direction: internal.Clock})
-
-	//fmt.Println(cb)
-	//fmt.Println()
-
-	//
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
-	return cb
+	right := cube.Algorithm{{Axis: cube.Xax, Idx: 1, Direction: cube.Counterclock}}
+	left := cube.Algorithm{{Axis: cube.Xax, Idx: -1, Direction: cube.Counterclock}}
+	down := cube.Algorithm{{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock}}
+	swap := down.Conjugate(right).
+		Concat(down.Inverse().Conjugate(left)).
+		Concat(down.Conjugate(right))
+
+	// Swap, turn the top, undo the swap and turn the top back
+	top := cube.Algorithm{{Axis: cube.Zax, Idx: 1, Direction: cube.Counterclock}}.Repeat(2)
+	return cb.MoveAll(cube.Commutator(swap, top))
 }
 
 func main() {
-	internal.PrintInColors(false)
-	cb := internal.New(3)
+	cb := cube.New(3)
 	fmt.Println(cb)
 	fmt.Println()
 
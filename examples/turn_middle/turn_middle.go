@@ -7,50 +7,49 @@ package main
 import (
 	"fmt"
 
-	"github.com/dfava/cube/internal"
+	"github.com/dfava/cube"
 )
 
 // Turn middle cubi at (0,1,1) so that the green and the yellow are reversed
-func turn_middle(cb internal.Cube) internal.Cube {
+func turn_middle(cb cube.Cube) cube.Cube {
 	// Turn
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Clock})
 	// Notice that these next four moves are a commutator!
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Clock})
 	//fmt.Println(cb)
 	//fmt.Println()
 
 	//
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Counterclock})
 	//fmt.Println(cb)
 	//fmt.Println()
 
 	// Reverse turn
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Counterclock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: -1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Xax, Idx: 0, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Counterclock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: -1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Xax, Idx: 0, Direction: cube.Clock})
 	//fmt.Println(cb)
 	//fmt.Println()
 
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
-	cb = cb.Move(internal.Move{Axis: internal.Zax, Idx: 1, Direction: internal.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock})
+	cb = cb.Move(cube.Move{Axis: cube.Zax, Idx: 1, Direction: cube.Clock})
 	return cb
 }
 
 func main() {
-	internal.PrintInColors(false)
-	cb := internal.New(3)
+	cb := cube.New(3)
 	fmt.Println(cb)
 	fmt.Println()
 
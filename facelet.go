@@ -0,0 +1,145 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Facelet strings are the format most solvers and robots exchange 3x3x3
+// cubes in: the letters of the faces U, R, F, D, L and B, one per sticker,
+// giving for each sticker the face whose color it has.  The faces are
+// listed in the order U, R, F, D, L, B, each as n rows of n stickers, as
+// seen when looking at the face with U on top, or with B on top for U and
+// F on top for D:
+//
+//	          U1 U2 U3
+//	          U4 U5 U6
+//	          U7 U8 U9
+//	L1 L2 L3  F1 F2 F3  R1 R2 R3  B1 B2 B3
+//	L4 L5 L6  F4 F5 F6  R4 R5 R6  B4 B5 B6
+//	L7 L8 L9  F7 F8 F9  R7 R8 R9  B7 B8 B9
+//	          D1 D2 D3
+//	          D4 D5 D6
+//	          D7 D8 D9
+//
+// The faces are those of the moves: U is the positive side of the z axis,
+// R of the x axis and F of the y axis.  The letters stand for the colors
+// of a solved cube that has not been rotated, in a color scheme: in the
+// Western scheme, yellow is U, orange R, green F, white D, red L and blue
+// B.
+
+const faceletFaces = "URFDLB"
+
+// Where the sticker on a row and column of a face is: the position of its
+// cubi and the axis it faces along
+func faceletPlace(n uint, face, r, c int) (vec, Axis) {
+	return cuboidPlace([3]uint{n, n, n}, face, r, c)
+}
+
+// Like faceletPlace, on a cuboid of the given size along each axis.  Faces
+// are as many stickers wide and high as the cuboid along the axes they
+// span.
+func cuboidPlace(dims [3]uint, face, r, c int) (vec, Axis) {
+	h := func(ax Axis) int { return int(dims[ax] / 2) }
+	up := func(ax Axis, i int) int { return coordValue(dims[ax], i) }
+	down := func(ax Axis, i int) int { return coordValue(dims[ax], int(dims[ax])-1-i) }
+	switch faceletFaces[face] {
+	case 'U':
+		return vec{up(Xax, c), up(Yax, r), h(Zax)}, Zax
+	case 'R':
+		return vec{h(Xax), down(Yax, c), down(Zax, r)}, Xax
+	case 'F':
+		return vec{up(Xax, c), h(Yax), down(Zax, r)}, Yax
+	case 'D':
+		return vec{up(Xax, c), down(Yax, r), -h(Zax)}, Zax
+	case 'L':
+		return vec{-h(Xax), up(Yax, c), down(Zax, r)}, Xax
+	default: // 'B'
+		return vec{down(Xax, c), -h(Yax), down(Zax, r)}, Yax
+	}
+}
+
+// Facelets returns the facelet string of the cube in the Western scheme.
+// See ColorScheme.Facelets.
+func (cube Cube) Facelets() (string, error) {
+	return Western().Facelets(cube)
+}
+
+// Facelets returns the facelet string of the cube, with n*n letters per
+// face, each the face that has the color of the sticker in the scheme.  On
+// a cube that has been rotated, the centers are not on the faces they
+// stand for, which most 3x3x3 solvers do not accept.  It fails if a
+// sticker has no color or if the scheme does not have each color once.
+func (s ColorScheme) Facelets(cube Cube) (string, error) {
+	if !s.valid() {
+		return "", fmt.Errorf("cube: invalid color scheme %v", s.Faces)
+	}
+	var letters [Blue + 1]byte
+	for f, c := range s.Faces {
+		letters[c] = faceletFaces[f]
+	}
+	n := int(cube.n)
+	var sb strings.Builder
+	sb.Grow(6 * n * n)
+	for face := range faceletFaces {
+		for r := range n {
+			for c := range n {
+				pv, ax := faceletPlace(cube.n, face, r, c)
+				color := cube.cubis[cubiIndex(cube.n, pv)].cv[ax].Abs()
+				if color < Green || color > Blue {
+					return "", fmt.Errorf("cube: sticker %d of face %c has no color", r*n+c+1, faceletFaces[face])
+				}
+				sb.WriteByte(letters[color])
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// ParseFacelets returns the cube of a facelet string in the Western
+// scheme.  See ColorScheme.ParseFacelets.
+func ParseFacelets(s string) (Cube, error) {
+	return Western().ParseFacelets(s)
+}
+
+// ParseFacelets returns the cube of a facelet string, colored with the
+// scheme.  The size of the cube is given by the length of the string,
+// which must be 6*n*n for some n larger than one.
+//
+// Any arrangement of letters is accepted.  Use Validate to tell whether
+// the cube can be solved.
+func (s ColorScheme) ParseFacelets(str string) (Cube, error) {
+	if !s.valid() {
+		return Cube{}, fmt.Errorf("cube: invalid color scheme %v", s.Faces)
+	}
+	n := uint(math.Round(math.Sqrt(float64(len(str)) / 6)))
+	if n < 2 || 6*n*n != uint(len(str)) {
+		return Cube{}, fmt.Errorf("cube: facelet string of length %d is not 6*n*n for any size n", len(str))
+	}
+	cube := s.New(n)
+	faces := s.Faces
+	i := 0
+	for face := range faceletFaces {
+		for r := range int(n) {
+			for c := range int(n) {
+				f := strings.IndexByte(faceletFaces, str[i])
+				if f < 0 {
+					return Cube{}, fmt.Errorf("cube: invalid facelet %q at position %d", str[i], i)
+				}
+				pv, ax := faceletPlace(n, face, r, c)
+				color := faces[f]
+				if pv[ax] < 0 {
+					color = -color
+				}
+				cube.cubis[cubiIndex(n, pv)].cv[ax] = color
+				i++
+			}
+		}
+	}
+	return cube, nil
+}
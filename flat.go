@@ -0,0 +1,528 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Flat is a data-structure that makes it easy to represent a cube
+// as a string (for example, when printing it to the screen).
+//
+// Flat is a representation in which the cube is "opened up" and
+// flattened into two dimensions.
+//
+// A cube of size n^3 is flattened into a 2D matrix of size (3*n)x(4*n).
+//
+// For example, the Rubik's cube, which is of size n=3^3, is flattened
+// into a 9x12 matrix.  Below we show a picture of the flattened Rubik's
+// cube.  The key for reading the picture below is:
+//
+//   u for `up`
+//   l for `left`
+//   c for `center`
+//   r for `right`
+//   b for `back`
+//   d for `down`
+//   . is unused (it does not represent any part of the 3D cube)
+//
+// . . . u u u . . . . . .
+// . . . u u u . . . . . .
+// . . . u u u . . . . . .
+// l l l c c c r r r b b b
+// l l l c c c r r r b b b
+// l l l c c c r r r b b b
+// . . . d d d . . . . . .
+// . . . d d d . . . . . .
+// . . . d d d . . . . . .
+
+type Flat [][]string
+
+func (fl Flat) Copy() Flat {
+	ret := make([][]string, len(fl))
+	for i := range fl {
+		ret[i] = make([]string, len(fl[i]))
+		copy(ret[i], fl[i])
+	}
+	return ret
+}
+
+// The size of the puzzle the Flat is the net of.  The Flat of a cuboid of
+// x by y by z has stickers on exactly the cells of its sides.  Others, such
+// as the frames of an animation, are taken to be the Flat of a cube.
+func (fl Flat) layout() (x, y, z int) {
+	rows, cols := len(fl), 0
+	if rows > 0 {
+		cols = len(fl[0])
+	}
+	for y := 1; 2*y < rows; y++ {
+		x, z := cols/2-y, rows-2*y
+		if x >= 1 && 2*(x+y) == cols && fl.fills(x, y, z) {
+			return x, y, z
+		}
+	}
+	n := rows / 3
+	return n, n, n
+}
+
+// Tells whether the sides of a cuboid of x by y by z are where the Flat has
+// stickers, and only those
+func (fl Flat) fills(x, y, z int) bool {
+	for r, row := range fl {
+		for c := range 2*x + 2*y {
+			inside := c >= y && c < y+x || r >= y && r < y+z
+			if blank := c >= len(row) || row[c] == "" || row[c] == " "; blank == inside {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String prints the Flat, one row per line, with a bar between the sides
+// of the middle band
+func (fl Flat) String() string {
+	x, y, z := fl.layout()
+	return fl.text(x, y, z, func(s string) string { return s })
+}
+
+// TextOptions tells how to print a Flat on a terminal.  The zero value
+// prints in 24-bit colors of the Western scheme.
+type TextOptions struct {
+	// Mode is how the stickers are colored
+	Mode ColorMode
+	// Scheme is the colors stickers are printed in, the Western scheme if
+	// zero
+	Scheme ColorScheme
+}
+
+// Text prints the Flat as String does, with the letter of each sticker
+// colored with ANSI escape codes.  With NoColor, it is the same as String.
+// FromString reads the Flat back from Text.
+func (fl Flat) Text(opts TextOptions) string {
+	x, y, z := fl.layout()
+	return fl.colorText(x, y, z, opts)
+}
+
+// Text of a Flat laid out for a puzzle of x by y by z, see Flat.Text
+func (fl Flat) colorText(x, y, z int, opts TextOptions) string {
+	if opts.Mode == NoColor {
+		return fl.text(x, y, z, func(s string) string { return s })
+	}
+	scheme := opts.Scheme.orWestern()
+	return fl.text(x, y, z, func(s string) string {
+		c, err := ParseColor(s)
+		if err != nil || c == zero {
+			return s
+		}
+		return scheme.ansi(c, opts.Mode, false) + colorLetters[c] + "\033[0m"
+	})
+}
+
+// Lays out the stickers of the Flat of a puzzle of x by y by z, each
+// printed by sticker.  Sides are x wide and y high on top and bottom, and
+// y or x wide and z high in the middle.
+func (fl Flat) text(x, y, z int, sticker func(string) string) string {
+	rows, cols := 2*y+z, 2*x+2*y
+	str := ""
+	for r := 0; r < rows; r++ {
+		c := 0
+		for ; c < cols; c++ {
+			if c == y || c == y+x || c == 2*y+x {
+				if r >= y && r < y+z {
+					str += fmt.Sprintf("| ")
+				} else {
+					str += fmt.Sprintf("  ")
+				}
+			}
+			if fl[r][c] == "" {
+				str += fmt.Sprintf("%s ", zero.String())
+			} else {
+				str += fmt.Sprintf("%s ", sticker(fl[r][c]))
+			}
+		}
+		if r != rows-1 && c == cols {
+			str += "\r\n"
+		}
+	}
+	return str
+}
+
+const (
+	row = iota
+	col
+)
+
+const (
+	neg = -1
+	pos = 1
+)
+
+func getSign(x int) int {
+	if x < 0 {
+		return neg
+	}
+	return pos
+}
+
+type projection struct {
+	axis   [2]Axis
+	offset [2]int
+	sign   [2]int
+	fun    [2]func(float64) float64
+	fname  [2]string
+}
+
+// Project down from a side of the cube (specified by an axis and a sign)
+// taking the size of the cube (n) into account.
+// Returns an offset into a Flat structure and other information
+// needed to calculate the projection
+func project(n uint, axis Axis, sign bool) projection {
+	var proj projection
+	switch axis {
+	case Xax:
+		proj.axis[row] = Zax
+		proj.axis[col] = Yax
+		if sign {
+			proj.offset[row] = int(n)
+			proj.offset[col] = int(n * 2)
+			proj.sign[row] = neg
+			proj.sign[col] = neg
+		} else {
+			proj.offset[row] = int(n)
+			proj.offset[col] = 0
+			proj.sign[row] = neg
+			proj.sign[col] = pos
+		}
+	case Yax:
+		proj.axis[row] = Zax
+		proj.axis[col] = Xax
+		if sign {
+			proj.offset[row] = int(n)
+			proj.offset[col] = int(n)
+			proj.sign[row] = neg
+			proj.sign[col] = pos
+		} else {
+			proj.offset[row] = int(n)
+			proj.offset[col] = int(n * 3)
+			proj.sign[row] = neg
+			proj.sign[col] = neg
+		}
+	case Zax:
+		proj.axis[row] = Yax
+		proj.axis[col] = Xax
+		if sign {
+			proj.offset[row] = 0
+			proj.offset[col] = int(n)
+			proj.sign[row] = pos
+			proj.sign[col] = pos
+		} else {
+			proj.offset[row] = int(n * 2)
+			proj.offset[col] = int(n)
+			proj.sign[row] = neg
+			proj.sign[col] = pos
+		}
+	}
+
+	for _, idx := range [...]int{row, col} {
+		if proj.sign[idx] == neg {
+			proj.fun[idx] = math.Ceil
+			proj.fname[idx] = "Ceil"
+		} else {
+			proj.fun[idx] = math.Floor
+			proj.fname[idx] = "Floor"
+		}
+	}
+	return proj
+}
+
+// Populate a Flat structure given a cubi.
+//
+// A cubi is composed of a location in space (captured by a vec) and
+// of a description on how to paint that location (captured by a cVec,
+// aka color vector).
+//
+// We use the vec and cVec to find the indices in Flat that need to be
+// populated, and we use the cVec to determine the string representation
+// the location's color.
+func (fl *Flat) paintCubi(cubi cubi, n uint) {
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		if cubi.cv[ax] == 0 {
+			continue
+		}
+		proj := project(n, ax, cubi.cv[ax] > 0)
+		r := int(n/2) + proj.sign[row]*cubi.pv[proj.axis[row]] + proj.offset[row]
+		c := int(n/2) + proj.sign[col]*cubi.pv[proj.axis[col]] + proj.offset[col]
+		if n%2 == 0 {
+			signArray := [3]float64{
+				float64(getSign(cubi.pv[Xax])),
+				float64(getSign(cubi.pv[Yax])),
+				float64(getSign(cubi.pv[Zax]))}
+			r += proj.sign[row] * int(proj.fun[row](-signArray[proj.axis[row]]*0.5))
+			c += proj.sign[col] * int(proj.fun[col](-signArray[proj.axis[col]]*0.5))
+		}
+		(*fl)[r][c] = cubi.cv[ax].Abs().String()
+	}
+}
+
+// PaintCube overwrites the Flat with a picture of the cube
+func (fl *Flat) PaintCube(cube Cube) {
+	(*fl) = make([][]string, cube.n*3)
+	for idx := range *fl {
+		(*fl)[idx] = make([]string, cube.n*4)
+	}
+	for idx := range cube.cubis {
+		fl.paintCubi(cube.cubis[idx], cube.n)
+	}
+}
+
+// FlatError is a problem found while reading a Flat from text
+type FlatError struct {
+	Line int // 1-based line of the problem
+	Col  int // 1-based byte column of the offending token, or 0 for the whole line
+	Msg  string
+}
+
+func (e *FlatError) Error() string {
+	if e.Col == 0 {
+		return fmt.Sprintf("flat: line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("flat: line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// A token on a line of text and the byte offset where it starts
+type flatToken struct {
+	text string
+	off  int
+}
+
+// Splits a line into tokens separated by white space, dropping the bars
+// that String puts between the sides of the cube
+func flatTokens(line string) []flatToken {
+	var ret []flatToken
+	start := -1
+	for i, r := range line + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && line[start:i] != "|" {
+			ret = append(ret, flatToken{line[start:i], start})
+		}
+		start = -1
+	}
+	return ret
+}
+
+// FromString reads the Flat from text in the format printed by String.
+// See FromReader.
+func (fl *Flat) FromString(cube string) error {
+	return fl.FromReader(strings.NewReader(cube))
+}
+
+// FromFile reads the Flat from a file in the format printed by String.
+// See FromReader.
+func (fl *Flat) FromFile(fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fl.FromReader(f)
+}
+
+// FromReader reads the Flat from text in the format printed by String:
+// n rows of n colors for the up side, n rows of 4*n colors for the left,
+// front, right and back sides, and n rows of n colors for the down side.
+// Colors are separated by white space, and bars between sides and blank
+// lines are ignored.  The size n is taken from the first row.
+//
+// Problems with the text are reported as a *FlatError with the line and
+// column where they are found, and leave the Flat untouched.
+func (fl *Flat) FromReader(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	var rows [][]string
+	var n, line int
+	for sc.Scan() {
+		line++
+		tokens := flatTokens(sc.Text())
+		if len(tokens) == 0 {
+			continue
+		}
+		if rows == nil {
+			n = len(tokens)
+			if n < 2 {
+				return &FlatError{line, 0, "a cube needs at least 2 colors per row"}
+			}
+		}
+		i := len(rows)
+		if i == 3*n {
+			return &FlatError{line, 0, fmt.Sprintf("too many rows for a cube of size %d", n)}
+		}
+		width, other := n, 4*n
+		if i >= n && i < 2*n {
+			width, other = 4*n, n
+		}
+		if len(tokens) != width {
+			if len(tokens) == other {
+				return &FlatError{line, 0, fmt.Sprintf("sides are not %d by %d", n, n)}
+			}
+			return &FlatError{line, 0, fmt.Sprintf("row has %d colors, expected %d", len(tokens), width)}
+		}
+		// The up and down sides are padded to the width of the others
+		row := make([]string, 4*n)
+		offset := 0
+		if width == n {
+			for j := range row {
+				row[j] = " "
+			}
+			offset = n
+		}
+		for j, tok := range tokens {
+			if c, err := ParseColor(tok.text); err != nil || c == zero {
+				return &FlatError{line, tok.off + 1, fmt.Sprintf("unknown color %q", tok.text)}
+			}
+			row[offset+j] = tok.text
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if rows == nil {
+		return &FlatError{line + 1, 0, "no rows"}
+	}
+	if len(rows) != 3*n {
+		return &FlatError{line + 1, 0, fmt.Sprintf("%d rows, expected %d for a cube of size %d", len(rows), 3*n, n)}
+	}
+	*fl = rows
+	return nil
+}
+
+// Cube reconstructs a cube from its flattened representation.  The Flat
+// of a cuboid that is not a cube gives the zero Cube, use Flat.Cuboid.
+func (fl Flat) Cube() Cube {
+	debug := false
+	if x, y, z := fl.layout(); x != y || y != z {
+		return Cube{}
+	}
+	n := len(fl) / 3
+	extremity := n / 2
+
+	preCube := make(map[vec]cVec)
+	for r := 0; r < n*3; r++ {
+		c := 0
+		for ; c < n*4; c++ {
+			if fl[r][c] == " " || fl[r][c] == "" {
+				continue
+			}
+			if debug {
+				fmt.Println()
+				fmt.Println(r, c, fl[r][c])
+			}
+
+			var axis Axis
+			var polarity bool
+			var pv vec
+			var cv cVec
+
+			if r < n {
+				// Size 5 (Yellow)
+				axis = Zax
+				polarity = true
+			} else if r >= 2*n {
+				// Size w (White)
+				axis = Zax
+				polarity = false
+			} else {
+				// Could be one of sides 4 (Red), 1 (Green), 3 (Orange), or 6 (Blue)
+				if c < n {
+					// Size 4 (Red)
+					axis = Xax
+					polarity = false
+				} else if c < 2*n {
+					// Size 1 (Green)
+					axis = Yax
+					polarity = true
+				} else if c < 3*n {
+					// Size 3 (Orange)
+					axis = Xax
+					polarity = true
+				} else {
+					// Size 6 (Blue)
+					axis = Yax
+					polarity = false
+				}
+			}
+			proj := project(uint(n), axis, polarity)
+			sign := -1
+			if polarity {
+				sign = 1
+			}
+			cv[axis] = Color(sign * func() int { v, _ := ParseColor(fl[r][c]); return int(v) }())
+			pv[axis] = sign * extremity
+			pv[proj.axis[row]] = (r - proj.offset[row] - n/2) * proj.sign[row]
+			pv[proj.axis[col]] = (c - proj.offset[col] - n/2) * proj.sign[col]
+			if n%2 == 0 {
+				rp := r - proj.offset[row]
+				cp := c - proj.offset[col]
+				if debug {
+					if pv[proj.axis[row]] == 0 {
+						fmt.Println("row:", rp, proj.axis[row], proj.sign[row], proj.fname[row])
+					}
+					if pv[proj.axis[col]] == 0 {
+						fmt.Println("col:", cp, proj.axis[col], proj.sign[col], proj.fname[col])
+					}
+				}
+				if rp >= n/2 {
+					pv[proj.axis[row]] += proj.sign[row]
+				}
+				if cp >= n/2 {
+					pv[proj.axis[col]] += proj.sign[col]
+				}
+			}
+
+			if debug {
+				fmt.Println(axis, polarity)
+				fmt.Println(pv, cv)
+			}
+			cvec, ok := preCube[pv]
+			if !ok {
+				if debug {
+					fmt.Println("Adding", cv, "to", pv)
+				}
+				preCube[pv] = cv
+			} else {
+				if debug {
+					fmt.Println("OR'ing", cv, "to", pv)
+				}
+				preCube[pv] = cvec.or(cv)
+			}
+		}
+	}
+	if n < 2 {
+		return Cube{n: uint(n)}
+	}
+	cube := New(uint(n))
+	for i := range cube.cubis {
+		cube.cubis[i].cv = cVec{}
+	}
+	for pvec, cvec := range preCube {
+		if debug {
+			fmt.Println(pvec, cvec)
+		}
+		cube.cubis[cubiIndex(cube.n, pvec)].cv = cvec
+	}
+
+	return cube
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+)
+
+// Where the stickers of a Flat are drawn, in pixels
+type netLayout struct {
+	size, gap  int
+	rows, cols int
+	rowSides   []int // the rows and columns where sides start, after the first
+	colSides   []int
+}
+
+func (fl Flat) netLayout(size, gap int) netLayout {
+	if size <= 0 {
+		size = DefaultStickerSize
+	}
+	x, y, z := fl.layout()
+	return netLayout{
+		size: size, gap: max(gap, 0),
+		rows: 2*y + z, cols: 2*x + 2*y,
+		rowSides: []int{y, y + z},
+		colSides: []int{y, y + x, 2*y + x},
+	}
+}
+
+// The top left corner of the stickers at a row or column.  There is a
+// margin of twice the gap around the net, and sides are twice the gap
+// apart.
+func (l netLayout) offset(i int, sides []int) int {
+	ret := 2*l.gap + i*(l.size+l.gap)
+	for _, s := range sides {
+		if i >= s {
+			ret += l.gap
+		}
+	}
+	return ret
+}
+
+func (l netLayout) x(c int) int { return l.offset(c, l.colSides) }
+func (l netLayout) y(r int) int { return l.offset(r, l.rowSides) }
+
+func (l netLayout) width() int {
+	if l.cols == 0 {
+		return 0
+	}
+	return l.x(l.cols-1) + l.size + 2*l.gap
+}
+
+func (l netLayout) height() int {
+	if l.rows == 0 {
+		return 0
+	}
+	return l.y(l.rows-1) + l.size + 2*l.gap
+}
+
+// ImageOptions tells how to draw a Flat as an image.  The zero value draws
+// stickers of DefaultStickerSize pixels with no gap between them, in the
+// colors of the Western scheme, on a transparent background.
+type ImageOptions struct {
+	// StickerSize is the width of a sticker in pixels, DefaultStickerSize
+	// if zero
+	StickerSize int
+	// Gap is the space between stickers in pixels.  Sides are twice as far
+	// apart.
+	Gap int
+	// Scheme is the colors stickers are drawn in, the Western scheme if
+	// zero
+	Scheme ColorScheme
+	// Background is the color around the stickers, transparent if nil
+	Background color.Color
+}
+
+// The palette of the images drawn with some options: the background, the
+// outline of the stickers, and then the colors from Green to Blue
+func (opts ImageOptions) palette() color.Palette {
+	ret := color.Palette{color.Transparent, color.Black}
+	if opts.Background != nil {
+		ret[0] = opts.Background
+	}
+	scheme := opts.Scheme.orWestern()
+	for c := Green; c <= Blue; c++ {
+		ret = append(ret, scheme.rgb(c))
+	}
+	return ret
+}
+
+// Image draws the Flat as an image of the same net String prints: up,
+// then left, front, right and back, then down.  It fails if a sticker is
+// not a color.
+func (fl Flat) Image(opts ImageOptions) (*image.Paletted, error) {
+	return fl.image(opts, opts.palette())
+}
+
+func (fl Flat) image(opts ImageOptions, palette color.Palette) (*image.Paletted, error) {
+	l := fl.netLayout(opts.StickerSize, opts.Gap)
+	img := image.NewPaletted(image.Rect(0, 0, l.width(), l.height()), palette)
+	for r := range fl {
+		for c, s := range fl[r] {
+			if s == "" || s == " " {
+				continue
+			}
+			sticker, err := ParseColor(s)
+			if err != nil {
+				return nil, fmt.Errorf("flat: unknown color %q at row %d, column %d", s, r, c)
+			}
+			x0, y0 := l.x(c), l.y(r)
+			for y := y0; y < y0+l.size; y++ {
+				for x := x0; x < x0+l.size; x++ {
+					index := uint8(1 + sticker)
+					if x == x0 || y == y0 || x == x0+l.size-1 || y == y0+l.size-1 {
+						index = 1
+					}
+					img.SetColorIndex(x, y, index)
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// WritePNG draws the Flat as a PNG image.  See Flat.Image.
+func (fl Flat) WritePNG(w io.Writer, opts ImageOptions) error {
+	img, err := fl.Image(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Image draws the cube opened up as a Flat.  See Flat.Image.
+func (cube Cube) Image(opts ImageOptions) (*image.Paletted, error) {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl.Image(opts)
+}
+
+// WritePNG draws the cube opened up as a Flat in a PNG image.  See
+// Flat.Image.
+func (cube Cube) WritePNG(w io.Writer, opts ImageOptions) error {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl.WritePNG(w, opts)
+}
+
+// GIFOptions tells how to animate moves in a GIF
+type GIFOptions struct {
+	ImageOptions
+	// Steps is the number of frames each move takes, as in MoveFrames,
+	// the size of the cube if zero
+	Steps int
+	// Delay is how long each frame is shown, 100ms if zero.  GIFs count
+	// time in hundredths of a second.
+	Delay time.Duration
+	// Pause is how long the cube is shown between moves, and at the start
+	// and the end, five times Delay if zero
+	Pause time.Duration
+	// LoopCount is the number of times the animation is played after the
+	// first, forever if zero and once if -1
+	LoopCount int
+}
+
+// WriteGIF writes an animated GIF of the moves being performed on the
+// cube, one after the other, with the frames of MoveFrames
+func (cube Cube) WriteGIF(w io.Writer, ms []Move, opts GIFOptions) error {
+	steps := opts.Steps
+	if steps <= 0 {
+		steps = int(cube.n)
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	pause := opts.Pause
+	if pause <= 0 {
+		pause = 5 * delay
+	}
+	hundredths := func(d time.Duration) int {
+		return max(int(d/(10*time.Millisecond)), 1)
+	}
+
+	palette := opts.palette()
+	anim := gif.GIF{LoopCount: opts.LoopCount}
+	add := func(fl Flat, d time.Duration) error {
+		img, err := fl.image(opts.ImageOptions, palette)
+		if err != nil {
+			return err
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, hundredths(d))
+		return nil
+	}
+
+	var fl Flat
+	fl.PaintCube(cube)
+	if err := add(fl, pause); err != nil {
+		return err
+	}
+	for _, m := range ms {
+		frames := cube.MoveFrames(m, steps)
+		for i, fl := range frames[1:] {
+			d := delay
+			if i == len(frames)-2 {
+				d = pause
+			}
+			if err := add(fl, d); err != nil {
+				return err
+			}
+		}
+		cube = cube.Move(m)
+	}
+	return gif.EncodeAll(w, &anim)
+}
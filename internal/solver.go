@@ -1,5 +0,0 @@
-package internal
-
-type Solver interface {
-	GetPath(start Cube, end Cube) []Move
-}
@@ -0,0 +1,149 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// IsometricOptions tells how to draw a cube in isometric projection.  The
+// zero value draws the up, front and right faces, with stickers DefaultScale
+// pixels wide, in 24-bit colors of the Western scheme.
+type IsometricOptions struct {
+	// Scale is the width of a sticker in pixels, DefaultScale if zero.  A
+	// character is one pixel wide and two pixels high.
+	Scale int
+	// View is a sequence of whole cube rotations in Singmaster notation,
+	// such as "y2" or "x' z", performed before drawing so that other faces
+	// are visible.  Rotations are of the cube, not of the viewer.
+	View string
+	// Mode is how the output is colored
+	Mode ColorMode
+	// Scheme is the colors stickers are drawn in, the Western scheme if
+	// zero
+	Scheme ColorScheme
+}
+
+// DefaultScale is the width of a sticker, in pixels, when
+// IsometricOptions does not give one
+const DefaultScale = 6
+
+// The faces drawn in isometric projection, and the axis each one faces
+// along.  Coordinates are continuous, from 0 at the negative end of each
+// axis to n at the positive end.
+var isometricFaces = [...]Axis{Zax, Yax, Xax}
+
+// Isometric draws the cube in isometric projection, as seen from above,
+// in front and to the right, using Unicode half blocks so that a
+// character holds two pixels.  It fails if the view is not made of whole
+// cube rotations.
+func (cube Cube) Isometric(opts IsometricOptions) (string, error) {
+	n := cube.n
+	if opts.View != "" {
+		ms, err := ParseMoves(n, opts.View)
+		if err != nil {
+			return "", err
+		}
+		if !isRotation(n, ms) {
+			return "", fmt.Errorf("cube: view %q is not a rotation of the cube", opts.View)
+		}
+		cube = cube.MoveAll(ms)
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	scheme := opts.Scheme.orWestern()
+
+	// Screen coordinates, in stickers, of a point (x, y, z) are
+	// (x-y)*cos(30), rightwards, and (x+y)*sin(30)-z, downwards
+	kx, ky := math.Cos(math.Pi/6), 0.5
+	fn := float64(n)
+	width := int(math.Ceil(2 * fn * kx * float64(scale)))
+	height := 2 * int(n) * scale
+
+	// The color of a pixel, zero for the background and -1 for the lines
+	// between stickers
+	border := 0.6 / float64(scale)
+	pixel := func(px, py int) Color {
+		sx := (float64(px)+0.5)/float64(scale) - fn*kx
+		sy := (float64(py)+0.5)/float64(scale) - fn
+		a := sx / kx
+		for _, ax := range isometricFaces {
+			var u, v float64 // the other two coordinates on the face
+			var pv vec
+			switch ax {
+			case Zax: // x-y = a, (x+y)*ky-n = sy
+				b := (sy + fn) / ky
+				u, v = (a+b)/2, (b-a)/2
+				pv[Zax] = int(n / 2)
+			case Yax: // x-n = a, (x+n)*ky-z = sy
+				u = a + fn
+				v = (u+fn)*ky - sy
+				pv[Yax] = int(n / 2)
+			case Xax: // n-y = a, (n+y)*ky-z = sy
+				u = fn - a
+				v = (fn+u)*ky - sy
+				pv[Xax] = int(n / 2)
+			}
+			if u < 0 || u >= fn || v < 0 || v >= fn {
+				continue
+			}
+			du, dv := u-math.Round(u), v-math.Round(v)
+			if math.Abs(du) < border || math.Abs(dv) < border {
+				return -1
+			}
+			others := [...]Axis{(ax + 1) % 3, (ax + 2) % 3}
+			if ax == Yax {
+				others = [...]Axis{Xax, Zax}
+			}
+			pv[others[0]] = coordValue(n, int(u))
+			pv[others[1]] = coordValue(n, int(v))
+			return cube.cubis[cubiIndex(n, pv)].cv[ax].Abs()
+		}
+		return zero
+	}
+
+	var sb strings.Builder
+	ansi := func(c Color, background bool) string {
+		return scheme.ansi(c, opts.Mode, background)
+	}
+	for row := 0; row < height/2; row++ {
+		for px := range width {
+			top, bottom := pixel(px, 2*row), pixel(px, 2*row+1)
+			switch {
+			case opts.Mode == NoColor && top > 0:
+				sb.WriteString(colorLetters[top])
+			case opts.Mode == NoColor && bottom > 0:
+				sb.WriteString(colorLetters[bottom])
+			case opts.Mode == NoColor && (top < 0 || bottom < 0):
+				sb.WriteString("·")
+			case top == zero && bottom == zero:
+				sb.WriteString(" ")
+			case top == zero:
+				sb.WriteString(ansi(bottom, false) + "▄\033[0m")
+			case bottom == zero:
+				sb.WriteString(ansi(top, false) + "▀\033[0m")
+			default:
+				sb.WriteString(ansi(top, false) + ansi(bottom, true) + "▀\033[0m")
+			}
+		}
+		sb.WriteString("\r\n")
+	}
+	return strings.TrimRight(sb.String(), "\r\n"), nil
+}
+
+// Tells whether moves turn a cube of size n as a whole
+func isRotation(n uint, ms []Move) bool {
+	moved := New(n).MoveAll(ms)
+	for _, rotated := range New(n).GetAllRotations() {
+		if moved.Equal(rotated) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,718 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KociembaSolver finds short solutions for the 3x3x3 cube with Herbert
+// Kociemba's two-phase algorithm.
+//
+// The first phase brings the cube into the group generated by U, D, R2,
+// L2, F2 and B2, where corners and edges are oriented and the edges of
+// the middle layer are in the middle layer.  The second phase solves the
+// cube within that group.  Both phases are iterative deepening searches
+// pruned by tables of distances, and longer first phases are tried until
+// the whole solution fits in MaxLength moves.
+//
+// As with PocketSolver, cubes that differ only by their orientation are
+// considered equal, and GetPath returns nil if the cubes are not of size
+// three, if there is no path between them, or if no path was found in
+// time.  Search also reports why.
+type KociembaSolver struct {
+	// MaxLength is the longest solution accepted, in the half turn
+	// metric.  Zero means DefaultMaxLength.
+	MaxLength int
+
+	// Timeout bounds the time spent searching.  Zero means no bound.
+	Timeout time.Duration
+
+	// Workers is the number of goroutines searching in parallel.  Zero
+	// means one per CPU.
+	Workers int
+
+	// CacheDir is a directory where the tables are stored, so they are
+	// only built once.  If empty, the tables are built in memory the
+	// first time they are needed, which takes a few seconds.
+	CacheDir string
+}
+
+// DefaultMaxLength is the longest solution KociembaSolver accepts by
+// default.  Solutions of that length are found in a fraction of a second.
+const DefaultMaxLength = 22
+
+const (
+	kTwists     = 2187  // 3^7 corner orientations
+	kFlips      = 2048  // 2^11 edge orientations
+	kSlices     = 495   // 12 choose 4 positions of the middle layer edges
+	kCornerPerm = 40320 // 8! corner permutations
+	kEdgePerm   = 40320 // 8! permutations of the U and D edges
+	kSlicePerm  = 24    // 4! permutations of the middle layer edges
+
+	kMoves       = 18 // every face, turned once, twice or three times
+	kPhase2Moves = 10
+)
+
+// The faces in Kociemba's order: U, R, F, D, L, B.  The opposite of face
+// i is face i+3.
+var kociembaFaces = [6]Move{
+	{Axis: Zax, Idx: 1, Direction: Clock},
+	{Axis: Xax, Idx: 1, Direction: Clock},
+	{Axis: Yax, Idx: 1, Direction: Clock},
+	{Axis: Zax, Idx: -1, Direction: Counterclock},
+	{Axis: Xax, Idx: -1, Direction: Counterclock},
+	{Axis: Yax, Idx: -1, Direction: Counterclock},
+}
+
+// The moves of the second phase, U, D and half turns of the other faces,
+// as indices into the moves of the first phase
+var kociembaPhase2 = [kPhase2Moves]int{0, 1, 2, 4, 7, 9, 10, 11, 13, 16}
+
+// Move i turns face i/3, i%3+1 times
+func kociembaTurn(i int) faceTurn {
+	return faceTurn{kociembaFaces[i/3], i%3 + 1}
+}
+
+// The cubies of each move
+var kociembaMoves = sync.OnceValue(func() [kMoves]cubieCube {
+	var ret [kMoves]cubieCube
+	for i := range ret {
+		ret[i] = solvedCubies
+		for _, m := range kociembaTurn(i).moves() {
+			ret[i] = ret[i].mult(moveCubies(m))
+		}
+	}
+	return ret
+})
+
+type kociembaTables struct {
+	// Coordinates after a move, indexed by coordinate*moves+move
+	twistMove     []uint16
+	flipMove      []uint16
+	sliceMove     []uint16
+	cornerMove    []uint16 // second phase moves only
+	edgeMove      []uint16 // second phase moves only
+	slicePermMove []uint16 // second phase moves only
+
+	// Lower bounds on the number of moves left
+	twistPrune  []uint8 // twist*kSlices+slice
+	flipPrune   []uint8 // flip*kSlices+slice
+	cornerPrune []uint8 // corner*kSlicePerm+slicePerm
+	edgePrune   []uint8 // edge*kSlicePerm+slicePerm
+}
+
+func newKociembaTables() *kociembaTables {
+	return &kociembaTables{
+		twistMove:     make([]uint16, kTwists*kMoves),
+		flipMove:      make([]uint16, kFlips*kMoves),
+		sliceMove:     make([]uint16, kSlices*kMoves),
+		cornerMove:    make([]uint16, kCornerPerm*kPhase2Moves),
+		edgeMove:      make([]uint16, kEdgePerm*kPhase2Moves),
+		slicePermMove: make([]uint16, kSlicePerm*kPhase2Moves),
+		twistPrune:    make([]uint8, kTwists*kSlices),
+		flipPrune:     make([]uint8, kFlips*kSlices),
+		cornerPrune:   make([]uint8, kCornerPerm*kSlicePerm),
+		edgePrune:     make([]uint8, kEdgePerm*kSlicePerm),
+	}
+}
+
+// The tables in the order they are stored on disk
+func (t *kociembaTables) all() []any {
+	return []any{
+		t.twistMove, t.flipMove, t.sliceMove,
+		t.cornerMove, t.edgeMove, t.slicePermMove,
+		t.twistPrune, t.flipPrune, t.cornerPrune, t.edgePrune,
+	}
+}
+
+// Coordinates of the first phase
+
+func twistCoord(c cubieCube) uint16 {
+	var ret uint16
+	for _, o := range c.corners.o[:nCorners-1] {
+		ret = ret*3 + uint16(o)
+	}
+	return ret
+}
+
+func flipCoord(c cubieCube) uint16 {
+	var ret uint16
+	for _, o := range c.edges.o[:nEdges-1] {
+		ret = ret*2 + uint16(o)
+	}
+	return ret
+}
+
+// Which slots hold the middle layer edges, 0 when they are home
+func sliceCoord(c cubieCube) uint16 {
+	ret, x := 0, 0
+	for j := nEdges - 1; j >= 0; j-- {
+		if c.edges.p[j] >= eFR {
+			ret += binomial(nEdges-1-j, x+1)
+			x++
+		}
+	}
+	return uint16(ret)
+}
+
+// Coordinates of the second phase, only meaningful within its group
+
+func cornerCoord(c cubieCube) uint16 {
+	return uint16(permRank(c.corners.p[:]))
+}
+
+func edgeCoord(c cubieCube) uint16 {
+	return uint16(permRank(c.edges.p[:eFR]))
+}
+
+func slicePermCoord(c cubieCube) uint16 {
+	var p [4]uint8
+	for i := range p {
+		p[i] = c.edges.p[eFR+i] - eFR
+	}
+	return uint16(permRank(p[:]))
+}
+
+// Builds a table of the coordinates reached by each move, given cubies
+// for every value of the coordinate.  Stops if the context is done.
+func moveTable(ctx context.Context, table []uint16, cubies []cubieCube, coord func(cubieCube) uint16, moves []cubieCube) error {
+	for i, c := range cubies {
+		if i%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		for m, mc := range moves {
+			table[i*len(moves)+m] = coord(c.mult(mc))
+		}
+	}
+	return nil
+}
+
+// Builds a table of distances to the solved cube, that is, to 0 on both
+// coordinates, by breadth first search.  Stops if the context is done.
+func pruneTable(ctx context.Context, table []uint8, move1, move2 []uint16, n2 int, nMoves int) error {
+	for i := range table {
+		table[i] = 0xff
+	}
+	table[0] = 0
+	queue := []int32{0}
+	for i := 0; len(queue) > 0; i++ {
+		if i%65536 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		state := int(queue[0])
+		queue = queue[1:]
+		c1, c2 := state/n2, state%n2
+		for m := range nMoves {
+			next := int(move1[c1*nMoves+m])*n2 + int(move2[c2*nMoves+m])
+			if table[next] == 0xff {
+				table[next] = table[state] + 1
+				queue = append(queue, int32(next))
+			}
+		}
+	}
+	return nil
+}
+
+// Builds the tables, unless the context is done first
+func (t *kociembaTables) build(ctx context.Context) error {
+	moves := kociembaMoves()
+	var moves2 [kPhase2Moves]cubieCube
+	for i, m := range kociembaPhase2 {
+		moves2[i] = moves[m]
+	}
+
+	twists := make([]cubieCube, kTwists)
+	for i := range twists {
+		twists[i] = solvedCubies
+		sum := 0
+		for j, x := nCorners-2, i; j >= 0; j-- {
+			twists[i].corners.o[j] = uint8(x % 3)
+			sum += x % 3
+			x /= 3
+		}
+		twists[i].corners.o[nCorners-1] = uint8((3 - sum%3) % 3)
+	}
+	if err := moveTable(ctx, t.twistMove, twists, twistCoord, moves[:]); err != nil {
+		return err
+	}
+
+	flips := make([]cubieCube, kFlips)
+	for i := range flips {
+		flips[i] = solvedCubies
+		sum := 0
+		for j, x := nEdges-2, i; j >= 0; j-- {
+			flips[i].edges.o[j] = uint8(x % 2)
+			sum += x % 2
+			x /= 2
+		}
+		flips[i].edges.o[nEdges-1] = uint8(sum % 2)
+	}
+	if err := moveTable(ctx, t.flipMove, flips, flipCoord, moves[:]); err != nil {
+		return err
+	}
+
+	slices := make([]cubieCube, kSlices)
+	for mask := 0; mask < 1<<nEdges; mask++ {
+		c := solvedCubies
+		inSlice, other := uint8(eFR), uint8(0)
+		for j := range nEdges {
+			if mask&(1<<j) != 0 {
+				c.edges.p[j] = inSlice
+				inSlice++
+			} else {
+				c.edges.p[j] = other
+				other++
+			}
+		}
+		if inSlice == eFR+4 && other == eFR {
+			slices[sliceCoord(c)] = c
+		}
+	}
+	if err := moveTable(ctx, t.sliceMove, slices, sliceCoord, moves[:]); err != nil {
+		return err
+	}
+
+	corners := make([]cubieCube, kCornerPerm)
+	for i := range corners {
+		corners[i] = solvedCubies
+		copy(corners[i].corners.p[:], permUnrank(nCorners, i))
+	}
+	if err := moveTable(ctx, t.cornerMove, corners, cornerCoord, moves2[:]); err != nil {
+		return err
+	}
+
+	edges := make([]cubieCube, kEdgePerm)
+	for i := range edges {
+		edges[i] = solvedCubies
+		copy(edges[i].edges.p[:eFR], permUnrank(eFR, i))
+	}
+	if err := moveTable(ctx, t.edgeMove, edges, edgeCoord, moves2[:]); err != nil {
+		return err
+	}
+
+	slicePerms := make([]cubieCube, kSlicePerm)
+	for i := range slicePerms {
+		slicePerms[i] = solvedCubies
+		for j, p := range permUnrank(4, i) {
+			slicePerms[i].edges.p[eFR+j] = eFR + p
+		}
+	}
+	if err := moveTable(ctx, t.slicePermMove, slicePerms, slicePermCoord, moves2[:]); err != nil {
+		return err
+	}
+
+	if err := pruneTable(ctx, t.twistPrune, t.twistMove, t.sliceMove, kSlices, kMoves); err != nil {
+		return err
+	}
+	if err := pruneTable(ctx, t.flipPrune, t.flipMove, t.sliceMove, kSlices, kMoves); err != nil {
+		return err
+	}
+	if err := pruneTable(ctx, t.cornerPrune, t.cornerMove, t.slicePermMove, kSlicePerm, kPhase2Moves); err != nil {
+		return err
+	}
+	return pruneTable(ctx, t.edgePrune, t.edgeMove, t.slicePermMove, kSlicePerm, kPhase2Moves)
+}
+
+const kociembaMagic = "cube kociemba tables v1\n"
+
+func (t *kociembaTables) read(r io.Reader) error {
+	magic := make([]byte, len(kociembaMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return err
+	}
+	if string(magic) != kociembaMagic {
+		return errors.New("not a table file")
+	}
+	for _, table := range t.all() {
+		if err := binary.Read(r, binary.LittleEndian, table); err != nil {
+			return err
+		}
+	}
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		return errors.New("trailing data in table file")
+	}
+	return nil
+}
+
+func (t *kociembaTables) write(w io.Writer) error {
+	if _, err := io.WriteString(w, kociembaMagic); err != nil {
+		return err
+	}
+	for _, table := range t.all() {
+		if err := binary.Write(w, binary.LittleEndian, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tables already loaded or built, per cache directory, with the error
+// storing them if any.  The lock is held while tables are loaded, and is a
+// channel so that waiting for it can be cancelled.
+type kociembaLoad struct {
+	t   *kociembaTables
+	err error
+}
+
+var (
+	kociembaLock   = make(chan struct{}, 1)
+	kociembaLoaded = make(map[string]kociembaLoad)
+)
+
+const kociembaFile = "kociemba.tables"
+
+// Returns the tables stored in dir, building and storing them if needed.
+// A corrupt file is rebuilt, but a file that cannot be read, or tables
+// that cannot be stored, are an error, every time the directory is used.
+// A build stops if the context is done, and the next load starts over.
+func loadKociembaTables(ctx context.Context, dir string) (*kociembaTables, error) {
+	select {
+	case kociembaLock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-kociembaLock }()
+	if l, ok := kociembaLoaded[dir]; ok {
+		return l.t, l.err
+	}
+
+	t := newKociembaTables()
+	if dir == "" {
+		if err := t.build(ctx); err != nil {
+			return nil, err
+		}
+		kociembaLoaded[dir] = kociembaLoad{t: t}
+		return t, nil
+	}
+
+	fname := filepath.Join(dir, kociembaFile)
+	f, err := os.Open(fname)
+	if err == nil {
+		err = t.read(bufio.NewReader(f))
+		f.Close()
+		if err == nil {
+			kociembaLoaded[dir] = kociembaLoad{t: t}
+			return t, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		kociembaLoaded[dir] = kociembaLoad{err: err}
+		return nil, err
+	}
+	if err := t.build(ctx); err != nil {
+		return nil, err
+	}
+	if err := saveKociembaTables(t, fname); err != nil {
+		kociembaLoaded[dir] = kociembaLoad{err: err}
+		return nil, err
+	}
+	kociembaLoaded[dir] = kociembaLoad{t: t}
+	return t, nil
+}
+
+// Writes the tables to a temporary file first, so readers never see a
+// partially written file
+func saveKociembaTables(t *kociembaTables, fname string) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(fname), kociembaFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	w := bufio.NewWriter(f)
+	if err = t.write(w); err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fname)
+}
+
+// Prepare loads the solver's tables, or builds them and stores them in
+// CacheDir.  Calling it is optional, GetPath prepares the tables when
+// needed, but it moves the cost out of the first call to GetPath and
+// tells why the tables cannot be read from or written to CacheDir, in
+// which case GetPath returns nil.
+func (s KociembaSolver) Prepare() error {
+	_, err := loadKociembaTables(context.Background(), s.CacheDir)
+	return err
+}
+
+type kociembaSearch struct {
+	t         *kociembaTables
+	c         cubieCube // the cube to solve
+	maxLength int
+	ctx       context.Context
+	nodes     int64
+	err       error // set once the context is done
+	path      []int // moves so far, of both phases
+
+	// When searching in parallel, the first move of the paths searched,
+	// and the smallest first move of the paths found by any worker
+	first int
+	found *atomic.Int32
+}
+
+// Returned by searches that were overtaken by a search of a smaller first
+// move
+var errOvertaken = errors.New("overtaken")
+
+// Reports whether the search was cancelled, checking the context every so
+// many nodes
+func (s *kociembaSearch) timeout() bool {
+	s.nodes++
+	if s.err == nil && s.nodes%1024 == 0 {
+		s.err = s.ctx.Err()
+		if s.err == nil && s.found != nil && int(s.found.Load()) < s.first {
+			s.err = errOvertaken
+		}
+	}
+	return s.err != nil
+}
+
+// Searches the paths of a depth in parallel, each worker taking the next
+// first move not yet searched.  The tables are only read, so the workers
+// share them.  The path found is the one the sequential search would find,
+// the one with the smallest first move, so the workers searching larger
+// first moves give up as soon as a path is found.
+func (s *kociembaSearch) parallel(twist, flip, slice uint16, togo int, workers int) bool {
+	t := s.t
+	var next, found atomic.Int32
+	found.Store(kMoves)
+	var wg sync.WaitGroup
+	paths := make([][]int, kMoves)
+	errs := make([]error, workers)
+	nodes := make([]int64, workers)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				m := int(next.Add(1)) - 1
+				if m >= kMoves || int(found.Load()) < m {
+					return
+				}
+				nt := t.twistMove[int(twist)*kMoves+m]
+				nf := t.flipMove[int(flip)*kMoves+m]
+				ns := t.sliceMove[int(slice)*kMoves+m]
+				if int(max(t.twistPrune[int(nt)*kSlices+int(ns)], t.flipPrune[int(nf)*kSlices+int(ns)])) >= togo {
+					continue
+				}
+				search := kociembaSearch{t: t, c: s.c, maxLength: s.maxLength, ctx: s.ctx, path: []int{m}, first: m, found: &found}
+				ok := search.phase1(nt, nf, ns, togo-1)
+				nodes[w] += search.nodes
+				if ok {
+					paths[m] = search.path
+					for {
+						f := found.Load()
+						if int(f) <= m || found.CompareAndSwap(f, int32(m)) {
+							return
+						}
+					}
+				}
+				if search.err != nil && search.err != errOvertaken {
+					errs[w] = search.err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for w := range workers {
+		s.nodes += nodes[w]
+		if errs[w] != nil {
+			s.err = errs[w]
+		}
+	}
+	if f := found.Load(); f < kMoves {
+		s.path = paths[f]
+		return true
+	}
+	return false
+}
+
+// Reports whether move m is redundant after the moves so far: turning
+// the same face twice in a row, or turning opposite faces in both orders
+func (s *kociembaSearch) redundant(m int) bool {
+	if len(s.path) == 0 {
+		return false
+	}
+	prev, face := s.path[len(s.path)-1]/3, m/3
+	return face == prev || face == prev-3
+}
+
+func (s *kociembaSearch) phase1(twist, flip, slice uint16, togo int) bool {
+	if s.timeout() {
+		return false
+	}
+	if togo == 0 {
+		if twist != 0 || flip != 0 || slice != 0 {
+			return false
+		}
+		// A phase one ending in a phase two move was already tried, shorter
+		if n := len(s.path); n > 0 {
+			last := s.path[n-1]
+			if last/3 == 0 || last/3 == 3 || last%3 == 1 {
+				return false
+			}
+		}
+		return s.startPhase2()
+	}
+	t := s.t
+	for m := range kMoves {
+		if s.redundant(m) {
+			continue
+		}
+		nt := t.twistMove[int(twist)*kMoves+m]
+		nf := t.flipMove[int(flip)*kMoves+m]
+		ns := t.sliceMove[int(slice)*kMoves+m]
+		if int(max(t.twistPrune[int(nt)*kSlices+int(ns)], t.flipPrune[int(nf)*kSlices+int(ns)])) >= togo {
+			continue
+		}
+		s.path = append(s.path, m)
+		if s.phase1(nt, nf, ns, togo-1) {
+			return true
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	return false
+}
+
+func (s *kociembaSearch) startPhase2() bool {
+	c := s.c
+	moves := kociembaMoves()
+	for _, m := range s.path {
+		c = c.mult(moves[m])
+	}
+	corner, edge, slicePerm := cornerCoord(c), edgeCoord(c), slicePermCoord(c)
+	t := s.t
+	h := int(max(t.cornerPrune[int(corner)*kSlicePerm+int(slicePerm)], t.edgePrune[int(edge)*kSlicePerm+int(slicePerm)]))
+	for togo := h; len(s.path)+togo <= s.maxLength; togo++ {
+		if s.phase2(corner, edge, slicePerm, togo) {
+			return true
+		}
+		if s.err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+func (s *kociembaSearch) phase2(corner, edge, slicePerm uint16, togo int) bool {
+	if s.timeout() {
+		return false
+	}
+	if togo == 0 {
+		return corner == 0 && edge == 0 && slicePerm == 0
+	}
+	t := s.t
+	for i, m := range kociembaPhase2 {
+		if s.redundant(m) {
+			continue
+		}
+		nc := t.cornerMove[int(corner)*kPhase2Moves+i]
+		ne := t.edgeMove[int(edge)*kPhase2Moves+i]
+		ns := t.slicePermMove[int(slicePerm)*kPhase2Moves+i]
+		if int(max(t.cornerPrune[int(nc)*kSlicePerm+int(ns)], t.edgePrune[int(ne)*kSlicePerm+int(ns)])) >= togo {
+			continue
+		}
+		s.path = append(s.path, m)
+		if s.phase2(nc, ne, ns, togo-1) {
+			return true
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	return false
+}
+
+func (s KociembaSolver) GetPath(start Cube, end Cube) []Move {
+	path, err := s.Search(context.Background(), start, end, Options{MaxDepth: s.MaxLength, Timeout: s.Timeout, Workers: s.Workers})
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// Search looks for a path of at most opts.MaxDepth moves, or of the
+// solver's MaxLength if zero.  The options' Timeout and Workers also
+// default to the solver's.  Only the half turn metric is supported.
+// Progress is reported each time the first phase deepens.  The path is
+// the same whatever the number of workers.
+//
+// The time spent loading or building the tables counts towards the
+// timeout, and the search stops building them if the context is done.
+// Errors reading or storing the tables in CacheDir are returned.
+func (s KociembaSolver) Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error) {
+	if opts.Metric != HTM {
+		return nil, ErrMetric
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = s.Timeout
+	}
+	if opts.Workers == 0 {
+		opts.Workers = s.Workers
+	}
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+	c, _, ok := toRelativeCubies(start, end)
+	if !ok {
+		return nil, ErrNoPath
+	}
+
+	t, err := loadKociembaTables(ctx, s.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	search := kociembaSearch{t: t, c: c, maxLength: opts.MaxDepth, ctx: ctx}
+	if search.maxLength <= 0 {
+		search.maxLength = s.MaxLength
+	}
+	if search.maxLength <= 0 {
+		search.maxLength = DefaultMaxLength
+	}
+	twist, flip, slice := twistCoord(c), flipCoord(c), sliceCoord(c)
+	h := int(max(t.twistPrune[int(twist)*kSlices+int(slice)], t.flipPrune[int(flip)*kSlices+int(slice)]))
+	workers := opts.workers()
+	for togo := h; togo <= search.maxLength; togo++ {
+		opts.progress(togo, search.nodes)
+		var ok bool
+		if workers > 1 && togo > 0 {
+			ok = search.parallel(twist, flip, slice, togo, workers)
+		} else {
+			ok = search.phase1(twist, flip, slice, togo)
+		}
+		if ok {
+			path := []Move{}
+			for _, m := range search.path {
+				path = append(path, kociembaTurn(m).moves()...)
+			}
+			return path, nil
+		}
+		if search.err != nil {
+			return nil, search.err
+		}
+	}
+	return nil, ErrNoPath
+}
@@ -0,0 +1,556 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// LayerSolver solves the 3x3x3 cube layer by layer, the way it is taught
+// to beginners: a cross on the D face, the corners of the first layer,
+// the edges of the second layer, a cross on the U face, then the last
+// layer is oriented and permuted.  Solutions are long, a hundred moves or
+// so, but every step can be followed by hand.
+//
+// As with the other solvers, cubes that differ only by their orientation
+// are considered equal.
+type LayerSolver struct{}
+
+// Stage is a step of a layer by layer solution
+type Stage struct {
+	Name        string
+	Moves       []Move
+	Explanation string
+}
+
+// A sequence of moves the learner performs as a whole
+type layerMacro struct {
+	name  string // in Singmaster notation
+	moves Algorithm
+	c     cubieCube
+	turn  bool // a turn of the U face
+}
+
+// The macros of each stage, most of them once per slot.  Slot k is the
+// one between the k-th and the (k+1)-th side face, counting F, R, B and L.
+type layerMacros struct {
+	turns  []layerMacro    // U, U2 and U'
+	sexy   [4][]layerMacro // R U R' U', 1 to 5 times
+	insert [4][]layerMacro // the edge at UF, then at UR, into FR
+	cross  [4]layerMacro   // F R U R' U' F'
+	sune   [4][]layerMacro // Sune, then Anti-Sune
+	aPerm  [4][]layerMacro // both directions
+	uPerm  [4][]layerMacro // both directions
+}
+
+// Pieces of the first two layers, per slot
+var (
+	layerCrossEdges = [4]int{eDF, eDR, eDB, eDL}
+	layerCorners    = [4]int{cDFR, cDRB, cDBL, cDLF}
+	layerEdges      = [4]int{eFR, eBR, eBL, eFL}
+)
+
+func newLayerMacro(a Algorithm) layerMacro {
+	c := solvedCubies
+	for _, m := range a {
+		c = c.mult(moveCubies(m))
+	}
+	return layerMacro{name: a.Format(3), moves: a, c: c}
+}
+
+// Parses an algorithm written for slot 0 and moves it to slot k, as if
+// the cube was rotated so that the k-th side face is in front
+func slotAlgorithm(s string, k int) Algorithm {
+	a, err := ParseAlgorithm(3, s)
+	if err != nil {
+		panic(err)
+	}
+	r := getRotationMatrix(Zax, Counterclock) // takes F to R
+	for range k {
+		for i := range a {
+			a[i] = a[i].transform(r)
+		}
+	}
+	return a
+}
+
+var getLayerMacros = sync.OnceValue(func() *layerMacros {
+	var ret layerMacros
+	for _, s := range []string{"U", "U2", "U'"} {
+		m := newLayerMacro(slotAlgorithm(s, 0))
+		m.turn = true
+		ret.turns = append(ret.turns, m)
+	}
+	for k := range 4 {
+		sexy := slotAlgorithm("R U R' U'", k)
+		for times := 1; times <= 5; times++ {
+			m := newLayerMacro(sexy.Repeat(times))
+			if times > 1 {
+				m.name = fmt.Sprintf("(%s)%d", sexy.Format(3), times)
+			}
+			ret.sexy[k] = append(ret.sexy[k], m)
+		}
+		for _, s := range []string{"U R U' R' U' F' U F", "U' F' U F U R U' R'"} {
+			ret.insert[k] = append(ret.insert[k], newLayerMacro(slotAlgorithm(s, k)))
+		}
+		ret.cross[k] = newLayerMacro(slotAlgorithm("F R U R' U' F'", k))
+		for _, s := range []string{"R U R' U R U2 R'", "R U2 R' U' R U' R'"} {
+			ret.sune[k] = append(ret.sune[k], newLayerMacro(slotAlgorithm(s, k)))
+		}
+		for _, s := range []string{"R' F R' B2 R F' R' B2 R2", "R2 B2 R F R' B2 R F' R"} {
+			ret.aPerm[k] = append(ret.aPerm[k], newLayerMacro(slotAlgorithm(s, k)))
+		}
+		for _, s := range []string{"R U' R U R U R U' R' U' R2", "R2 U R U R' U' R' U' R' U R'"} {
+			ret.uPerm[k] = append(ret.uPerm[k], newLayerMacro(slotAlgorithm(s, k)))
+		}
+	}
+	return &ret
+})
+
+// The state of a layer by layer solution
+type layerSearch struct {
+	ctx   context.Context
+	nodes int64 // the macros performed so far
+	err   error // why the search stopped, if it did
+}
+
+// Finds the shortest sequence of macros, up to maxDepth of them, that
+// brings the cube to the goal.  Two turns of the U face are never
+// performed in a row.  Returns false if there is none or if the context
+// is done, in which case s.err is set.
+func (s *layerSearch) macros(c cubieCube, goal func(cubieCube) bool, macros []layerMacro, maxDepth int) ([]layerMacro, cubieCube, bool) {
+	var path []layerMacro
+	var found cubieCube
+	var dfs func(c cubieCube, depth int) bool
+	dfs = func(c cubieCube, depth int) bool {
+		if goal(c) {
+			found = c
+			return true
+		}
+		if depth == 0 {
+			return false
+		}
+		for _, m := range macros {
+			if m.turn && len(path) > 0 && path[len(path)-1].turn {
+				continue
+			}
+			s.nodes++
+			if s.nodes%1024 == 0 {
+				s.err = s.ctx.Err()
+			}
+			if s.err != nil {
+				return false
+			}
+			path = append(path, m)
+			if dfs(c.mult(m.c), depth-1) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	for depth := 0; depth <= maxDepth && s.err == nil; depth++ {
+		if dfs(c, depth) {
+			return path, found, true
+		}
+	}
+	return nil, c, false
+}
+
+func cornerSolved(c cubieCube, slot int) bool {
+	return c.corners.p[slot] == uint8(slot) && c.corners.o[slot] == 0
+}
+
+func edgeSolved(c cubieCube, slot int) bool {
+	return c.edges.p[slot] == uint8(slot) && c.edges.o[slot] == 0
+}
+
+// Distances to the solved cross, for the first k cross edges, k=1..4.
+// The state of k edges is the sum of their positions times 24^i, where
+// the position of an edge is twice its slot plus its flip.
+var getCrossTables = sync.OnceValue(func() [4][]uint8 {
+	var edgeMove [2 * nEdges][kMoves]int
+	for m, b := range kociembaMoves() {
+		for i := range nEdges {
+			for o := range 2 {
+				edgeMove[2*int(b.edges.p[i])+o][m] = 2*i + (o+int(b.edges.o[i]))%2
+			}
+		}
+	}
+	var ret [4][]uint8
+	size := 1
+	for k := range ret {
+		size *= 2 * nEdges
+		ret[k] = make([]uint8, size)
+		for i := range ret[k] {
+			ret[k][i] = 0xff
+		}
+		solved := crossState(solvedCubies, k+1)
+		ret[k][solved] = 0
+		queue := []int{solved}
+		for len(queue) > 0 {
+			state := queue[0]
+			queue = queue[1:]
+			for m := range kMoves {
+				next, x, mult := 0, state, 1
+				for range k + 1 {
+					next += edgeMove[x%(2*nEdges)][m] * mult
+					x /= 2 * nEdges
+					mult *= 2 * nEdges
+				}
+				if ret[k][next] == 0xff {
+					ret[k][next] = ret[k][state] + 1
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return ret
+})
+
+// The state of the first k cross edges
+func crossState(c cubieCube, k int) int {
+	ret, mult := 0, 1
+	for _, piece := range layerCrossEdges[:k] {
+		for slot := range nEdges {
+			if int(c.edges.p[slot]) == piece {
+				ret += (2*slot + int(c.edges.o[slot])) * mult
+			}
+		}
+		mult *= 2 * nEdges
+	}
+	return ret
+}
+
+var colorNames = [...]string{"", "green", "white", "orange", "red", "yellow", "blue"}
+
+// Names a piece of the end cube by its colors, starting from U or D
+func pieceName(end Cube, pos vec, axes []Axis) string {
+	var names []string
+	for _, cbi := range end.cubis {
+		if cbi.pv == pos {
+			for _, ax := range axes {
+				names = append(names, colorNames[cbi.cv[ax].Abs()])
+			}
+		}
+	}
+	kind := "corner"
+	if len(axes) == 2 {
+		kind = "edge"
+	}
+	return strings.Join(names, "-") + " " + kind
+}
+
+func cornerName(end Cube, slot int) string {
+	return pieceName(end, cornerSlots[slot].pos, cornerSlots[slot].axes[:])
+}
+
+func edgeName(end Cube, slot int) string {
+	return pieceName(end, edgeSlots[slot].pos, edgeSlots[slot].axes[:])
+}
+
+// Collects the moves and the explanation of a stage
+type stageWriter struct {
+	stage Stage
+	lines []string
+}
+
+func (w *stageWriter) add(what string, steps []string, ms []Move) {
+	w.stage.Moves = append(w.stage.Moves, ms...)
+	how := strings.Join(steps, ", then ")
+	if len(steps) == 0 {
+		how = "already done"
+	}
+	w.lines = append(w.lines, fmt.Sprintf("  %s: %s", what, how))
+}
+
+func (w *stageWriter) addMacros(what string, macros []layerMacro) {
+	var steps []string
+	var ms []Move
+	for _, m := range macros {
+		steps = append(steps, m.name)
+		ms = append(ms, m.moves...)
+	}
+	w.add(what, steps, ms)
+}
+
+func (w *stageWriter) done() Stage {
+	w.stage.Explanation += "\n" + strings.Join(w.lines, "\n")
+	if w.stage.Moves == nil {
+		w.stage.Moves = []Move{}
+	}
+	return w.stage
+}
+
+// Stages returns the steps that take the start cube to the end cube, or
+// nil if the cubes are not of size three or if there is no path between
+// them.  Stages that have nothing to do have no moves.
+func (s LayerSolver) Stages(start Cube, end Cube) []Stage {
+	stages, err := s.stages(context.Background(), start, end, Options{})
+	if err != nil {
+		return nil
+	}
+	return stages
+}
+
+// The stages of a solution, see Search
+func (s LayerSolver) stages(ctx context.Context, start Cube, end Cube, opts Options) ([]Stage, error) {
+	c, end, ok := toRelativeCubies(start, end)
+	if !ok {
+		return nil, ErrNoPath
+	}
+	h := int(end.n / 2)
+	var down, up string
+	for _, cbi := range end.cubis {
+		if cbi.pv == (vec{0, 0, -h}) {
+			down = colorNames[cbi.cv[Zax].Abs()]
+		} else if cbi.pv == (vec{0, 0, h}) {
+			up = colorNames[cbi.cv[Zax].Abs()]
+		}
+	}
+	macros := getLayerMacros()
+	search := layerSearch{ctx: ctx}
+	var stages []Stage
+
+	// Starts a stage, once the previous one is done
+	begin := func(stage Stage) (stageWriter, error) {
+		if err := ctx.Err(); err != nil {
+			return stageWriter{}, err
+		}
+		opts.progress(len(stages), search.nodes)
+		return stageWriter{stage: stage}, nil
+	}
+
+	// Adds the shortest sequence of macros that reaches the goal to a stage
+	step := func(w *stageWriter, what string, macros []layerMacro, maxDepth int, goal func(cubieCube) bool) error {
+		path, next, ok := search.macros(c, goal, macros, maxDepth)
+		if search.err != nil {
+			return search.err
+		}
+		if !ok {
+			return ErrNoPath
+		}
+		c = next
+		w.addMacros(what, path)
+		return nil
+	}
+
+	// The cross, one edge at a time, each in as few moves as possible
+	w, err := begin(Stage{
+		Name:        "cross",
+		Explanation: fmt.Sprintf("Make a %s cross on the D face, with each edge matching the center next to it.", down),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tables := getCrossTables()
+	moves := kociembaMoves()
+	for k, slot := range layerCrossEdges {
+		var ms []Move
+		for d := tables[k][crossState(c, k+1)]; d > 0; d-- {
+			for m := range kMoves {
+				next := c.mult(moves[m])
+				if tables[k][crossState(next, k+1)] == d-1 {
+					ms = append(ms, kociembaTurn(m).moves()...)
+					c = next
+					break
+				}
+			}
+		}
+		var steps []string
+		if len(ms) > 0 {
+			steps = []string{FormatMoves(3, ms)}
+		}
+		w.add(edgeName(end, slot), steps, ms)
+	}
+	stages = append(stages, w.done())
+	cross := func(c cubieCube) bool {
+		for _, e := range layerCrossEdges {
+			if !edgeSolved(c, e) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// The corners of the first layer
+	w, err = begin(Stage{
+		Name: "first layer corners",
+		Explanation: fmt.Sprintf("Complete the %s face with its corners.  Take a corner out of a wrong slot with R U R' U', "+
+			"bring it above its slot with U, then repeat R U R' U' until it is in place.  "+
+			"The moves are the ones seen when holding the slot at the front right.", down),
+	})
+	if err != nil {
+		return nil, err
+	}
+	all := append([]layerMacro{}, macros.turns...)
+	for k := range 4 {
+		all = append(all, macros.sexy[k]...)
+	}
+	for k, slot := range layerCorners {
+		goal := func(c cubieCube) bool {
+			for _, corner := range layerCorners[:k+1] {
+				if !cornerSolved(c, corner) {
+					return false
+				}
+			}
+			return cross(c)
+		}
+		if err := step(&w, cornerName(end, slot), all, 3, goal); err != nil {
+			return nil, err
+		}
+	}
+	stages = append(stages, w.done())
+	firstLayer := func(c cubieCube) bool {
+		for _, corner := range layerCorners {
+			if !cornerSolved(c, corner) {
+				return false
+			}
+		}
+		return cross(c)
+	}
+
+	// The edges of the second layer
+	w, err = begin(Stage{
+		Name: "second layer",
+		Explanation: "Insert the edges of the middle layer.  Bring an edge to the U face, above the center of its color, " +
+			"then insert it into the slot at the front right: with U R U' R' U' F' U F from above the F center, " +
+			"or with U' F' U F U R U' R' from above the R center.  An edge in a wrong slot is taken out the same way.  " +
+			"The moves are the ones seen when holding the slot at the front right.",
+	})
+	if err != nil {
+		return nil, err
+	}
+	all = append([]layerMacro{}, macros.turns...)
+	for k := range 4 {
+		all = append(all, macros.insert[k]...)
+	}
+	for k, slot := range layerEdges {
+		goal := func(c cubieCube) bool {
+			for _, e := range layerEdges[:k+1] {
+				if !edgeSolved(c, e) {
+					return false
+				}
+			}
+			return firstLayer(c)
+		}
+		if err := step(&w, edgeName(end, slot), all, 3, goal); err != nil {
+			return nil, err
+		}
+	}
+	stages = append(stages, w.done())
+	firstTwoLayers := func(c cubieCube) bool {
+		for _, e := range layerEdges {
+			if !edgeSolved(c, e) {
+				return false
+			}
+		}
+		return firstLayer(c)
+	}
+
+	// The cross of the last layer
+	w, err = begin(Stage{
+		Name: "last layer cross",
+		Explanation: fmt.Sprintf("Make a %s cross on the U face with F R U R' U' F', "+
+			"from the side where the edges already facing up make a line from left to right, or an L at the back left.", up),
+	})
+	if err != nil {
+		return nil, err
+	}
+	all = append([]layerMacro{}, macros.turns...)
+	all = append(all, macros.cross[:]...)
+	err = step(&w, "edges", all, 4, func(c cubieCube) bool {
+		for _, e := range [...]int{eUR, eUF, eUL, eUB} {
+			if c.edges.o[e] != 0 {
+				return false
+			}
+		}
+		return firstTwoLayers(c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	stages = append(stages, w.done())
+
+	// Corners facing up
+	w, err = begin(Stage{
+		Name: "last layer orientation",
+		Explanation: fmt.Sprintf("Turn the corners of the last layer so that the whole U face is %s, "+
+			"with Sune (R U R' U R U2 R') and Anti-Sune (R U2 R' U' R U' R').", up),
+	})
+	if err != nil {
+		return nil, err
+	}
+	all = append([]layerMacro{}, macros.turns...)
+	for k := range 4 {
+		all = append(all, macros.sune[k]...)
+	}
+	err = step(&w, "corners", all, 4, func(c cubieCube) bool {
+		for _, corner := range [...]int{cURF, cUFL, cULB, cUBR} {
+			if c.corners.o[corner] != 0 {
+				return false
+			}
+		}
+		return firstTwoLayers(c) && c.edges.o == solvedEdges.o
+	})
+	if err != nil {
+		return nil, err
+	}
+	stages = append(stages, w.done())
+
+	// Corners, then edges, in place
+	w, err = begin(Stage{
+		Name: "last layer permutation",
+		Explanation: "Put the corners of the last layer in place with the A permutation (R' F R' B2 R F' R' B2 R2), " +
+			"then cycle the edges with the U permutation (R U' R U R U R U' R' U' R2).",
+	})
+	if err != nil {
+		return nil, err
+	}
+	all = append([]layerMacro{}, macros.turns...)
+	for k := range 4 {
+		all = append(all, macros.aPerm[k]...)
+	}
+	if err := step(&w, "corners", all, 4, func(c cubieCube) bool { return firstTwoLayers(c) && c.corners == solvedCorners }); err != nil {
+		return nil, err
+	}
+	all = nil
+	for k := range 4 {
+		all = append(all, macros.uPerm[k]...)
+	}
+	if err := step(&w, "edges", all, 2, func(c cubieCube) bool { return c == solvedCubies }); err != nil {
+		return nil, err
+	}
+	return append(stages, w.done()), nil
+}
+
+func (s LayerSolver) GetPath(start Cube, end Cube) []Move {
+	path, err := s.Search(context.Background(), start, end, Options{})
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// Search ignores the options' MaxDepth, Metric and Workers, as the solver
+// does not look for short paths.  The context is checked as the macros of
+// each stage are searched, and progress is reported as each stage starts,
+// with the number of stages done as the depth.
+func (s LayerSolver) Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error) {
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+	stages, err := s.stages(ctx, start, end, opts)
+	if err != nil {
+		return nil, err
+	}
+	path := []Move{}
+	for _, stage := range stages {
+		path = append(path, stage.Moves...)
+	}
+	return path, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The JSON form of a cube is its size and the colors of its faces, each
+// face a string of n*n color letters in the order of facelet strings:
+//
+//	{"size":2,"faces":{"U":"yyyy","R":"oooo","F":"gggg","D":"wwww","L":"rrrr","B":"bbbb"}}
+//
+// The text form of a cube is its facelet string.
+
+type cubeJSON struct {
+	Size  uint      `json:"size"`
+	Faces facesJSON `json:"faces"`
+}
+
+type facesJSON struct {
+	U, R, F, D, L, B string
+}
+
+// The faces in the order of faceletFaces
+func (f *facesJSON) faces() [6]*string {
+	return [...]*string{&f.U, &f.R, &f.F, &f.D, &f.L, &f.B}
+}
+
+// MarshalJSON encodes a cube as its size and the colors of its faces
+func (cube Cube) MarshalJSON() ([]byte, error) {
+	ret := cubeJSON{Size: cube.n}
+	n := int(cube.n)
+	for face, s := range ret.Faces.faces() {
+		var sb strings.Builder
+		for r := range n {
+			for c := range n {
+				pv, ax := faceletPlace(cube.n, face, r, c)
+				sb.WriteString(colorLetters[cube.cubis[cubiIndex(cube.n, pv)].cv[ax].Abs()])
+			}
+		}
+		*s = sb.String()
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON decodes a cube encoded by MarshalJSON
+func (cube *Cube) UnmarshalJSON(data []byte) error {
+	var v cubeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n := v.Size
+	if n < 2 {
+		return fmt.Errorf("cube: invalid size %d in JSON", n)
+	}
+	for face, s := range v.Faces.faces() {
+		if uint(len(*s)) != n*n {
+			return fmt.Errorf("cube: face %c has %d colors in JSON, expected %d", faceletFaces[face], len(*s), n*n)
+		}
+	}
+	ret := New(n)
+	for face, s := range v.Faces.faces() {
+		for i := range len(*s) {
+			pv, ax := faceletPlace(n, face, i/int(n), i%int(n))
+			c, err := ParseColor((*s)[i : i+1])
+			if err != nil || c == zero {
+				return fmt.Errorf("cube: invalid color %q on face %c in JSON", (*s)[i], faceletFaces[face])
+			}
+			if pv[ax] < 0 {
+				c = -c
+			}
+			ret.cubis[cubiIndex(n, pv)].cv[ax] = c
+		}
+	}
+	*cube = ret
+	return nil
+}
+
+// MarshalText encodes a cube as its facelet string
+func (cube Cube) MarshalText() ([]byte, error) {
+	s, err := cube.Facelets()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText decodes a cube from its facelet string
+func (cube *Cube) UnmarshalText(text []byte) error {
+	ret, err := ParseFacelets(string(text))
+	if err != nil {
+		return err
+	}
+	*cube = ret
+	return nil
+}
+
+// MarshalText encodes a move as Move.String does.  A move does not know
+// the size of its cube, which Singmaster notation depends on, so use
+// MoveList to encode moves in notation.
+func (m Move) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a move encoded by MarshalText
+func (m *Move) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) != 3 {
+		return fmt.Errorf("cube: invalid move %q", text)
+	}
+	ax, err := ParseAxis(fields[0])
+	if err != nil {
+		return fmt.Errorf("cube: invalid move %q: %w", text, err)
+	}
+	idx, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("cube: invalid move %q: %w", text, err)
+	}
+	dir, err := ParseDirection(fields[2])
+	if err != nil {
+		return fmt.Errorf("cube: invalid move %q: %w", text, err)
+	}
+	*m = Move{Axis: ax, Idx: idx, Direction: dir}
+	return nil
+}
+
+// MoveList is a sequence of moves for a cube of a given size.  It is
+// encoded in JSON as the size and the moves in Singmaster notation, one
+// token per move, so that decoding gives back the very same moves:
+//
+//	{"size":3,"moves":"R U R' U'"}
+type MoveList struct {
+	Size  uint
+	Moves []Move
+}
+
+type moveListJSON struct {
+	Size  uint   `json:"size"`
+	Moves string `json:"moves"`
+}
+
+// MarshalJSON encodes the moves in notation.  It fails if a move is not
+// valid for the size.
+func (l MoveList) MarshalJSON() ([]byte, error) {
+	tokens := make([]string, len(l.Moves))
+	for i, m := range l.Moves {
+		if _, ok := layerNumber(l.Size, m.Idx); !ok || m.Axis > Zax {
+			return nil, fmt.Errorf("cube: move %v is not valid for a cube of size %d", m, l.Size)
+		}
+		tokens[i] = FormatMoves(l.Size, []Move{m})
+	}
+	return json.Marshal(moveListJSON{l.Size, strings.Join(tokens, " ")})
+}
+
+// UnmarshalJSON decodes moves encoded by MarshalJSON.  Any notation
+// understood by ParseMoves is accepted.
+func (l *MoveList) UnmarshalJSON(data []byte) error {
+	var v moveListJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Size < 2 {
+		return fmt.Errorf("cube: invalid size %d in JSON", v.Size)
+	}
+	ms, err := ParseMoves(v.Size, v.Moves)
+	if err != nil {
+		return err
+	}
+	*l = MoveList{v.Size, ms}
+	return nil
+}
+
+// MarshalText encodes the flat as String prints it, with the colors as
+// plain letters
+func (fl Flat) MarshalText() ([]byte, error) {
+	plain := fl.Copy()
+	for _, row := range plain {
+		for c, s := range row {
+			if color, err := ParseColor(s); err == nil {
+				row[c] = colorLetters[color]
+			}
+		}
+	}
+	return []byte(plain.String()), nil
+}
+
+// UnmarshalText decodes a flat as FromString does
+func (fl *Flat) UnmarshalText(text []byte) error {
+	return fl.FromString(string(text))
+}
@@ -0,0 +1,385 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Singmaster (WCA) notation maps onto the coordinate system as follows:
+//
+//	R, L  right and left faces   (x>0, x<0)
+//	F, B  front and back faces   (y>0, y<0)
+//	U, D  up and down faces      (z>0, z<0)
+//	M     inner layers between L and R, turning like L
+//	E     inner layers between U and D, turning like D
+//	S     inner layers between F and B, turning like F
+//	x y z whole cube rotations, turning like R, U and F
+//
+// A face letter turns one layer clockwise as seen from that face.  The
+// letter can be followed by w to turn more than one layer (Rw turns two
+// layers, 3Rw turns three), and a number in front of a face letter alone
+// selects a single inner layer (3R turns only the third layer from the
+// right).  Lowercase face letters are the same as their wide counterpart.
+// A trailing 2 makes a half turn and a trailing ' reverses the direction.
+
+// NotationError reports a malformed move string
+type NotationError struct {
+	Pos   int    // byte offset of the offending token
+	Token string // the offending token
+	Msg   string
+}
+
+func (e *NotationError) Error() string {
+	return fmt.Sprintf("notation: %s %q at position %d", e.Msg, e.Token, e.Pos)
+}
+
+type notationFace struct {
+	axis     Axis
+	positive bool // the side of the axis the face is on
+}
+
+var notationFaces = map[byte]notationFace{
+	'R': {Xax, true},
+	'L': {Xax, false},
+	'F': {Yax, true},
+	'B': {Yax, false},
+	'U': {Zax, true},
+	'D': {Zax, false},
+}
+
+// Slices turn the same way as the face named on the right
+var notationSlices = map[byte]byte{
+	'M': 'L',
+	'E': 'D',
+	'S': 'F',
+}
+
+// Rotations turn the same way as the face named on the right
+var notationRotations = map[byte]byte{
+	'x': 'R',
+	'y': 'U',
+	'z': 'F',
+}
+
+// Returns the index of the k-th layer counting from the positive end of
+// an axis, starting with k=1
+func layerIdx(n uint, k int) int {
+	h := int(n) / 2
+	idx := h - (k - 1)
+	if n%2 == 0 && idx <= 0 {
+		idx--
+	}
+	return idx
+}
+
+// The inverse of layerIdx
+func layerNumber(n uint, idx int) (int, bool) {
+	h := int(n) / 2
+	if idx < -h || idx > h || (n%2 == 0 && idx == 0) {
+		return 0, false
+	}
+	k := h - idx + 1
+	if n%2 == 0 && idx < 0 {
+		k--
+	}
+	return k, true
+}
+
+// Direction that turns a face clockwise when looking at the face
+func faceDirection(f notationFace) Direction {
+	if f.positive {
+		return Clock
+	}
+	return Counterclock
+}
+
+// ParseMoves reads a sequence of moves in Singmaster notation for a cube
+// of size n.  Half turns and wide turns are expanded into the quarter
+// turns of single layers that make them up.  The prime of a half turn may
+// come before or after the 2, as in R2' and R'2.
+func ParseMoves(n uint, s string) ([]Move, error) {
+	var ret []Move
+	pos := 0
+	for pos < len(s) {
+		if s[pos] == ' ' || s[pos] == '\t' || s[pos] == '\n' || s[pos] == '\r' {
+			pos++
+			continue
+		}
+		ms, next, err := parseMove(n, s, pos)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, ms...)
+		pos = next
+	}
+	return ret, nil
+}
+
+// Parses the move starting at s[start], returns the moves and the
+// position right after the move
+func parseMove(n uint, s string, start int) ([]Move, int, error) {
+	pos := start
+	fail := func(msg string) ([]Move, int, error) {
+		end := pos
+		if end == start && end < len(s) {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		return nil, 0, &NotationError{Pos: start, Token: s[start:end], Msg: msg}
+	}
+
+	// Optional layer count in front of a face letter
+	for pos < len(s) && s[pos] >= '0' && s[pos] <= '9' {
+		pos++
+	}
+	hasPrefix := pos > start
+	prefix, _ := strconv.Atoi(s[start:pos])
+	if pos == len(s) {
+		return fail("missing move")
+	}
+
+	letter := s[pos]
+	pos++
+	wide := false
+	if pos < len(s) && s[pos] == 'w' {
+		wide = true
+		pos++
+	}
+
+	var f notationFace
+	var layers []int
+	face, isFace := notationFaces[letter]
+	lower, isLower := notationFaces[letter-'a'+'A']
+	isLower = isLower && letter >= 'a' && letter <= 'z'
+	switch {
+	case isFace:
+		f = face
+		switch {
+		case hasPrefix && prefix < 1:
+			return fail("invalid layer")
+		case !hasPrefix && wide:
+			prefix = 2
+		case !hasPrefix:
+			prefix = 1
+		}
+		if prefix > int(n) {
+			return fail(fmt.Sprintf("layer out of range for a %dx%d cube", n, n))
+		}
+		if wide {
+			for k := 1; k <= prefix; k++ {
+				layers = append(layers, k)
+			}
+		} else {
+			layers = []int{prefix}
+		}
+	case isLower:
+		if hasPrefix || wide {
+			return fail("unexpected prefix or w on a lowercase move")
+		}
+		f = lower
+		layers = []int{1, 2}
+	case notationSlices[letter] != 0:
+		if hasPrefix || wide {
+			return fail("unexpected prefix or w on a slice move")
+		}
+		if n < 3 {
+			return fail(fmt.Sprintf("no inner layers on a %dx%d cube", n, n))
+		}
+		f = notationFaces[notationSlices[letter]]
+		for k := 2; k < int(n); k++ {
+			layers = append(layers, k)
+		}
+	case notationRotations[letter] != 0:
+		if hasPrefix || wide {
+			return fail("unexpected prefix or w on a rotation")
+		}
+		f = notationFaces[notationRotations[letter]]
+		for k := 1; k <= int(n); k++ {
+			layers = append(layers, k)
+		}
+	default:
+		pos = start
+		return fail("unknown move")
+	}
+
+	// Suffixes: a half turn and/or a prime, in either order
+	times := 1
+	if pos < len(s) && s[pos] == '2' {
+		times = 2
+		pos++
+	}
+	dir := faceDirection(f)
+	if pos < len(s) && s[pos] == '\'' {
+		dir = !dir
+		pos++
+	} else if strings.HasPrefix(s[pos:], "’") {
+		dir = !dir
+		pos += len("’")
+	}
+	if times == 1 && dir != faceDirection(f) && pos < len(s) && s[pos] == '2' {
+		times = 2
+		pos++
+	}
+	if pos < len(s) && s[pos] >= '0' && s[pos] <= '9' {
+		for pos < len(s) && s[pos] >= '0' && s[pos] <= '9' {
+			pos++
+		}
+		return fail("invalid turn amount")
+	}
+
+	var ret []Move
+	for t := 0; t < times; t++ {
+		for _, k := range layers {
+			idx := layerIdx(n, k)
+			if !f.positive {
+				idx = -idx
+			}
+			ret = append(ret, Move{Axis: f.axis, Idx: idx, Direction: dir})
+		}
+	}
+	return ret, pos, nil
+}
+
+// FormatMoves writes a sequence of moves for a cube of size n in
+// Singmaster notation.  Consecutive moves about the same axis are merged,
+// so the result is not necessarily the shortest string, but parsing it
+// with ParseMoves always gives the same cube.  Moves that are not valid
+// for n are written out with Move.String, in angle brackets.
+func FormatMoves(n uint, ms []Move) string {
+	var tokens []string
+	for i := 0; i < len(ms); {
+		j := i
+		for j < len(ms) && ms[j].Axis == ms[i].Axis {
+			j++
+		}
+		tokens = append(tokens, formatAxis(n, ms[i].Axis, ms[i:j])...)
+		i = j
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Formats moves that all turn about the same axis
+func formatAxis(n uint, ax Axis, ms []Move) []string {
+	var tokens []string
+
+	// Quarter turns, seen from the positive end of the axis, per layer
+	amount := make([]int, n+1)
+	for _, m := range ms {
+		k, ok := layerNumber(n, m.Idx)
+		if !ok {
+			tokens = append(tokens, "<"+m.String()+">")
+			continue
+		}
+		if m.Direction == Clock {
+			amount[k] = (amount[k] + 1) % 4
+		} else {
+			amount[k] = (amount[k] + 3) % 4
+		}
+	}
+
+	var posLetter, negLetter, sliceLetter, rotLetter byte
+	for letter, f := range notationFaces {
+		if f.axis == ax && f.positive {
+			posLetter = letter
+		} else if f.axis == ax {
+			negLetter = letter
+		}
+	}
+	for letter, face := range notationSlices {
+		if notationFaces[face].axis == ax {
+			sliceLetter = letter
+		}
+	}
+	for letter, face := range notationRotations {
+		if notationFaces[face].axis == ax {
+			rotLetter = letter
+		}
+	}
+	// Amount of turns as seen from the letter's own face
+	seenFrom := func(letter byte, a int) int {
+		f := notationFaces[letter]
+		if face, ok := notationSlices[letter]; ok {
+			f = notationFaces[face]
+		} else if face, ok := notationRotations[letter]; ok {
+			f = notationFaces[face]
+		}
+		if f.positive {
+			return a
+		}
+		return (4 - a) % 4
+	}
+	token := func(prefix string, letter byte, wide bool, a int) string {
+		str := prefix + string(letter)
+		if wide {
+			str += "w"
+		}
+		return str + [...]string{"", "", "2", "'"}[seenFrom(letter, a)]
+	}
+	// Turns the first width layers counting from a face
+	outer := func(letter byte, width int, a int) string {
+		switch width {
+		case 1:
+			return token("", letter, false, a)
+		case 2:
+			return token("", letter, true, a)
+		}
+		return token(strconv.Itoa(width), letter, true, a)
+	}
+
+	all := true
+	for k := 1; k <= int(n); k++ {
+		all = all && amount[k] == amount[1]
+	}
+	if all {
+		if amount[1] != 0 {
+			tokens = append(tokens, token("", rotLetter, false, amount[1]))
+		}
+		return tokens
+	}
+
+	if a := amount[1]; a != 0 {
+		width := 1
+		for width < int(n) && amount[width+1] == a {
+			width++
+		}
+		tokens = append(tokens, outer(posLetter, width, a))
+		for k := 1; k <= width; k++ {
+			amount[k] = 0
+		}
+	}
+	if a := amount[n]; a != 0 {
+		width := 1
+		for width < int(n) && amount[int(n)-width] == a {
+			width++
+		}
+		tokens = append(tokens, outer(negLetter, width, a))
+		for k := int(n) - width + 1; k <= int(n); k++ {
+			amount[k] = 0
+		}
+	}
+
+	inner := n >= 3
+	for k := 2; k < int(n); k++ {
+		inner = inner && amount[k] != 0 && amount[k] == amount[2]
+	}
+	if inner {
+		return append(tokens, token("", sliceLetter, false, amount[2]))
+	}
+	for k := 2; k < int(n); k++ {
+		if amount[k] == 0 {
+			continue
+		}
+		if k <= (int(n)+1)/2 {
+			tokens = append(tokens, token(strconv.Itoa(k), posLetter, false, amount[k]))
+		} else {
+			tokens = append(tokens, token(strconv.Itoa(int(n)-k+1), negLetter, false, amount[k]))
+		}
+	}
+	return tokens
+}
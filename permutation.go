@@ -0,0 +1,130 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Permutation is a sequence of moves compiled, for a cube of some size,
+// into where each of its 6*n*n stickers goes.  Permutations compose, so
+// a whole algorithm is compiled once and then performed on any number of
+// cubes in time proportional to the number of stickers, however long the
+// algorithm.
+//
+// Stickers are numbered as in the binary encoding of cubes, see
+// MarshalBinary.
+type Permutation struct {
+	n   uint
+	dst []int32 // the sticker at index i goes to index dst[i]
+}
+
+// Where each sticker is on a cube of some size: the index of its cubi and
+// the axis it faces along
+type stickerPlace struct {
+	cubi int32
+	axis Axis
+}
+
+var stickerPlaces sync.Map // size to []stickerPlace
+
+func getStickerPlaces(n uint) []stickerPlace {
+	if t, ok := stickerPlaces.Load(n); ok {
+		return t.([]stickerPlace)
+	}
+	t := make([]stickerPlace, 6*n*n)
+	h := int(n / 2)
+	for i, cbi := range New(n).cubis {
+		for ax, x := range cbi.pv {
+			if x == h || x == -h {
+				t[stickerIndex(n, cbi.pv, Axis(ax))] = stickerPlace{int32(i), Axis(ax)}
+			}
+		}
+	}
+	actual, _ := stickerPlaces.LoadOrStore(n, t)
+	return actual.([]stickerPlace)
+}
+
+// Compile turns moves into a permutation of the stickers of a cube of
+// size n.  Compiling no moves gives the identity.
+func Compile(n uint, ms []Move) Permutation {
+	p := Permutation{n: n, dst: make([]int32, 6*n*n)}
+	for i := range p.dst {
+		p.dst[i] = int32(i)
+	}
+	places := getStickerPlaces(n)
+	positions := New(n).cubis
+	for _, m := range ms {
+		mat := getRotationMatrix(m.Axis, m.Direction)
+		next := Permutation{n: n, dst: make([]int32, len(p.dst))}
+		for i, place := range places {
+			pv := positions[place.cubi].pv
+			next.dst[i] = int32(i)
+			if pv[m.Axis] != m.Idx {
+				continue
+			}
+
+			// The sticker, as a cubi with a single color, once turned
+			var sticker cubi
+			sticker.pv = pv
+			sticker.cv[place.axis] = 1
+			sticker = mat.mult(sticker)
+			for ax, c := range sticker.cv {
+				if c != 0 {
+					next.dst[i] = int32(stickerIndex(n, sticker.pv, Axis(ax)))
+				}
+			}
+		}
+		p = p.Then(next)
+	}
+	return p
+}
+
+// Size returns the size of the cubes the permutation is for
+func (p Permutation) Size() uint {
+	return p.n
+}
+
+// Then returns the permutation that performs p followed by q.  It panics
+// if they are not for cubes of the same size.
+func (p Permutation) Then(q Permutation) Permutation {
+	if p.n != q.n {
+		panic(fmt.Sprintf("permutations for cubes of size %d and %d", p.n, q.n))
+	}
+	ret := Permutation{n: p.n, dst: make([]int32, len(p.dst))}
+	for i, j := range p.dst {
+		ret.dst[i] = q.dst[j]
+	}
+	return ret
+}
+
+// Inverse returns the permutation that undoes p
+func (p Permutation) Inverse() Permutation {
+	ret := Permutation{n: p.n, dst: make([]int32, len(p.dst))}
+	for i, j := range p.dst {
+		ret.dst[j] = int32(i)
+	}
+	return ret
+}
+
+// Permute returns the cube with its stickers permuted.  It panics if the
+// permutation is for cubes of another size.
+func (cube Cube) Permute(p Permutation) Cube {
+	if p.n != cube.n {
+		panic(fmt.Sprintf("permutation for cubes of size %d on a cube of size %d", p.n, cube.n))
+	}
+	ret := cube.Copy()
+	places := getStickerPlaces(cube.n)
+	for i, j := range p.dst {
+		from, to := places[i], places[j]
+		c := cube.cubis[from.cubi].cv[from.axis].Abs()
+		if ret.cubis[to.cubi].pv[to.axis] < 0 {
+			c = -c
+		}
+		ret.cubis[to.cubi].cv[to.axis] = c
+	}
+	return ret
+}
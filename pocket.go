@@ -0,0 +1,271 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// PocketSolver finds shortest solutions for the 2x2x2 cube, also known
+// as the pocket cube.
+//
+// The corner at DBL is kept in place and only R, U and F are turned,
+// which leaves 7! * 3^6 = 3,674,160 states.  The distance of every state
+// to the solved cube is computed, by breadth first search, the first
+// time a metric is used, which takes a second or so.  Solving a cube is
+// then a matter of walking down the distances.
+//
+// Cubes that differ only by their orientation are considered equal, so
+// GetPath takes the start cube to the end cube up to a rotation of the
+// whole cube.  GetPath returns nil if the cubes are not of size two or if
+// there is no path between them, as when a corner is twisted.
+type PocketSolver struct {
+	Metric Metric
+}
+
+const (
+	pocketPerms = 5040 // 7!
+	pocketOris  = 729  // 3^6
+)
+
+type pocketTable struct {
+	lock  chan struct{} // held while the table is built
+	built atomic.Bool
+	moves []faceTurn
+	perm  [][]uint16 // perm[coordinate][move]
+	ori   [][]uint16 // ori[coordinate][move]
+	dist  []uint8    // dist[perm*pocketOris+ori]
+}
+
+var pocketTables = [2]pocketTable{ // one per metric
+	{lock: make(chan struct{}, 1)},
+	{lock: make(chan struct{}, 1)},
+}
+
+// The slots that move, every slot but DBL
+var pocketSlots = [7]int{cURF, cUFL, cULB, cUBR, cDFR, cDLF, cDRB}
+
+func pocketPermCoord(c cornerCubies) uint16 {
+	var pieces [7]uint8
+	for i, slot := range pocketSlots {
+		pieces[i] = c.p[slot]
+		if pieces[i] == cDRB {
+			pieces[i] = cDBL
+		}
+	}
+	return uint16(permRank(pieces[:]))
+}
+
+func pocketOriCoord(c cornerCubies) uint16 {
+	var ret uint16
+	for _, slot := range pocketSlots[:6] {
+		ret = ret*3 + uint16(c.o[slot])
+	}
+	return ret
+}
+
+func pocketCubies(perm, ori uint16) cornerCubies {
+	ret := solvedCorners
+	pieces := permUnrank(7, int(perm))
+	for i, slot := range pocketSlots {
+		ret.p[slot] = pieces[i]
+		if pieces[i] == cDBL {
+			ret.p[slot] = cDRB
+		}
+	}
+	sum := 0
+	for i := 5; i >= 0; i-- {
+		ret.o[pocketSlots[i]] = uint8(ori % 3)
+		sum += int(ori % 3)
+		ori /= 3
+	}
+	ret.o[cDRB] = uint8((3 - sum%3) % 3)
+	return ret
+}
+
+// Builds the table unless it is built already, and returns the number of
+// states reached building it.  A build that is cancelled is thrown away,
+// and the next search starts over.
+func (t *pocketTable) load(ctx context.Context, metric Metric, opts Options) (int64, error) {
+	if t.built.Load() {
+		return 0, nil
+	}
+	select {
+	case t.lock <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	defer func() { <-t.lock }()
+	if t.built.Load() {
+		return 0, nil
+	}
+	reached, err := t.init(ctx, metric, opts)
+	if err != nil {
+		t.moves, t.perm, t.ori, t.dist = nil, nil, nil, nil
+		return 0, err
+	}
+	t.built.Store(true)
+	return reached, nil
+}
+
+// Progress is reported at each depth of the breadth first search, with
+// the number of states reached
+func (t *pocketTable) init(ctx context.Context, metric Metric, opts Options) (int64, error) {
+	t.moves = nil
+	for _, ax := range [...]Axis{Xax, Zax, Yax} {
+		face := Move{Axis: ax, Idx: 1, Direction: Clock}
+		t.moves = append(t.moves, faceTurn{face, 1})
+		if metric == HTM {
+			t.moves = append(t.moves, faceTurn{face, 2})
+		}
+		t.moves = append(t.moves, faceTurn{face, 3})
+	}
+	cubies := make([]cornerCubies, len(t.moves))
+	for i, ft := range t.moves {
+		cubies[i] = solvedCorners
+		for _, m := range ft.moves() {
+			cubies[i] = cubies[i].mult(moveCorners(2, m))
+		}
+	}
+
+	t.perm = make([][]uint16, pocketPerms)
+	for p := range t.perm {
+		c := pocketCubies(uint16(p), 0)
+		t.perm[p] = make([]uint16, len(t.moves))
+		for m := range t.moves {
+			t.perm[p][m] = pocketPermCoord(c.mult(cubies[m]))
+		}
+	}
+	t.ori = make([][]uint16, pocketOris)
+	for o := range t.ori {
+		c := pocketCubies(0, uint16(o))
+		t.ori[o] = make([]uint16, len(t.moves))
+		for m := range t.moves {
+			t.ori[o][m] = pocketOriCoord(c.mult(cubies[m]))
+		}
+	}
+
+	// Breadth first search from the solved cube, one depth at a time
+	t.dist = make([]uint8, pocketPerms*pocketOris)
+	for i := range t.dist {
+		t.dist[i] = 0xff
+	}
+	t.dist[0] = 0
+	reached := int64(1)
+	for depth, found := uint8(0), true; found; depth++ {
+		opts.progress(int(depth), reached)
+		found = false
+		for state, d := range t.dist {
+			if state%65536 == 0 {
+				if err := ctx.Err(); err != nil {
+					return reached, err
+				}
+			}
+			if d != depth {
+				continue
+			}
+			p, o := state/pocketOris, state%pocketOris
+			for m := range t.moves {
+				next := int(t.perm[p][m])*pocketOris + int(t.ori[o][m])
+				if t.dist[next] == 0xff {
+					t.dist[next] = depth + 1
+					reached++
+					found = true
+				}
+			}
+		}
+	}
+	return reached, nil
+}
+
+func (s PocketSolver) GetPath(start Cube, end Cube) []Move {
+	path, err := s.Search(context.Background(), start, end, Options{Metric: s.Metric})
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// Search looks for a shortest path in opts.Metric, the solver's Metric
+// being ignored.  While the distance table is built, progress is reported
+// at each depth of its breadth first search, with the states reached so
+// far, and the build stops if the context is done.  Progress is then
+// reported once more, with the depth of the start cube.  The table is
+// built by one search at a time and the others wait for it.
+func (s PocketSolver) Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error) {
+	if opts.Metric < HTM || opts.Metric > QTM {
+		return nil, ErrMetric
+	}
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+	if start.n != 2 || end.n != 2 {
+		return nil, ErrNoPath
+	}
+
+	// Turn the end cube so that its DBL corner matches the start cube's
+	var dbl cubi
+	for _, cbi := range start.cubis {
+		if cbi.pv == (vec{-1, -1, -1}) {
+			dbl = cbi
+		}
+	}
+	var endCubies cornerCubies
+	f, ok := cornerFaces(start)
+	if !ok {
+		return nil, ErrNoPath
+	}
+	found := false
+	for _, rotated := range end.GetAllRotations() {
+		for _, cbi := range rotated.cubis {
+			if cbi == dbl {
+				endCubies, found = toCornerCubies(rotated, f)
+			}
+		}
+		if found {
+			break
+		}
+	}
+	startCubies, ok := toCornerCubies(start, f)
+	if !found || !ok {
+		return nil, ErrNoPath
+	}
+	c := endCubies.inverse().mult(startCubies)
+	twist := 0
+	for _, o := range c.o {
+		twist += int(o)
+	}
+	if twist%3 != 0 {
+		return nil, ErrNoPath
+	}
+
+	t := &pocketTables[opts.Metric]
+	nodes, err := t.load(ctx, opts.Metric, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p, o := pocketPermCoord(c), pocketOriCoord(c)
+	d := t.dist[int(p)*pocketOris+int(o)]
+	if d == 0xff || (opts.MaxDepth > 0 && int(d) > opts.MaxDepth) {
+		return nil, ErrNoPath
+	}
+	opts.progress(int(d), nodes+1)
+	path := []Move{}
+	for ; d > 0; d-- {
+		for m, ft := range t.moves {
+			np, no := t.perm[p][m], t.ori[o][m]
+			if t.dist[int(np)*pocketOris+int(no)] == d-1 {
+				path = append(path, ft.moves()...)
+				p, o = np, no
+				break
+			}
+		}
+	}
+	return path, nil
+}
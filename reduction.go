@@ -0,0 +1,631 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"context"
+	"sync"
+)
+
+// ReductionSolver solves cubes of any size by reducing them to a 3x3x3
+// cube: the centers of each face are brought together, then the pieces
+// of each edge are paired, and what is left is solved like a 3x3x3 cube.
+// Cubes of size two and three are handed to PocketSolver and to the
+// Kociemba solver directly.
+//
+// Centers and edge pieces are moved with commutators A B A' B', where A
+// turns an inner layer and B moves a single piece of that layer.  Such a
+// commutator cycles three pieces and leaves the rest of the cube alone,
+// and setup moves bring any three pieces to where it cycles them.
+//
+// Three-cycles cannot fix an odd permutation of edge pieces.  That is the
+// parity of cubes of even size, OLL parity, which is fixed up front with a
+// quarter turn of an inner layer.  On cubes of even size the edges are
+// also paired so that two of them are swapped when the corners need it,
+// or the reduced cube could not be solved, which is PLL parity.
+//
+// As with the other solvers, cubes that differ only by their orientation
+// are considered equal.  Solutions are long: the start cube is solved,
+// then taken to the end cube.
+type ReductionSolver struct {
+	// Kociemba solves the reduced cube, and cubes of size three
+	Kociemba KociembaSolver
+}
+
+type reductionTables struct {
+	positions []vec
+	index     map[vec]int
+	moves     []Move    // every quarter turn
+	perms     [][]int32 // perms[m][i] is where move m takes the piece at position i
+	orbits    []*reductionOrbit
+}
+
+// Center pieces, or edge pieces, that can take each other's place.  The
+// rotations of the whole cube take any of the 24 positions of an orbit to
+// any other in exactly one way.
+type reductionOrbit struct {
+	wing      bool
+	layer     int   // for edge pieces, an inner layer the orbit is in
+	positions []int // indices into reductionTables.positions
+	local     map[int]int
+	rotTo     [][]uint8 // rotTo[p][q] is the rotation that takes position p to q
+
+	gens      []int     // indices of the moves that move pieces of the orbit
+	genPerms  [][]uint8 // where each of the gens takes each position
+	templates []Algorithm
+
+	// For every triple of positions, the generator that brings it closer
+	// to where a template cycles it, or the template that does
+	gen      []int16
+	template []int16
+}
+
+const (
+	reductionTemplate    = -1
+	reductionUnreachable = -2
+)
+
+var (
+	reductionMu     sync.Mutex
+	reductionLoaded = make(map[uint]*reductionTables)
+)
+
+func getReductionTables(n uint) *reductionTables {
+	reductionMu.Lock()
+	defer reductionMu.Unlock()
+	if t, ok := reductionLoaded[n]; ok {
+		return t
+	}
+	t := newReductionTables(n)
+	reductionLoaded[n] = t
+	return t
+}
+
+// Compares positions, lexicographically
+func vecLess(a, b vec) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func newReductionTables(n uint) *reductionTables {
+	t := &reductionTables{index: make(map[vec]int)}
+	for _, cbi := range New(n).cubis {
+		t.index[cbi.pv] = len(t.positions)
+		t.positions = append(t.positions, cbi.pv)
+	}
+	h := int(n / 2)
+	var inner []int
+	moveIndex := make(map[Move]int)
+	for _, ax := range [...]Axis{Xax, Yax, Zax} {
+		for idx := -h; idx <= h; idx++ {
+			if n%2 == 0 && idx == 0 {
+				continue
+			}
+			if ax == Xax && idx > -h && idx < h {
+				inner = append(inner, idx)
+			}
+			for _, dir := range [...]Direction{Clock, Counterclock} {
+				m := Move{Axis: ax, Idx: idx, Direction: dir}
+				mat := getRotationMatrix(ax, dir)
+				perm := make([]int32, len(t.positions))
+				for i, p := range t.positions {
+					perm[i] = int32(i)
+					if p[ax] == idx {
+						perm[i] = int32(t.index[mat.mult(cubi{pv: p}).pv])
+					}
+				}
+				moveIndex[m] = len(t.moves)
+				t.moves = append(t.moves, m)
+				t.perms = append(t.perms, perm)
+			}
+		}
+	}
+
+	// Orbits, named after their smallest position
+	rots := cubeRotations()
+	byKey := make(map[vec]*reductionOrbit)
+	for i, p := range t.positions {
+		outer, zeros := 0, 0
+		for _, x := range p {
+			if x == h || x == -h {
+				outer++
+			} else if x == 0 {
+				zeros++
+			}
+		}
+		wing := outer == 2 && zeros == 0
+		if !wing && (outer != 1 || zeros == 2) {
+			continue // corners, middle edges and middle centers
+		}
+		key := p
+		for _, r := range rots {
+			if q := r.mult(cubi{pv: p}).pv; vecLess(q, key) {
+				key = q
+			}
+		}
+		o, ok := byKey[key]
+		if !ok {
+			o = &reductionOrbit{wing: wing, local: make(map[int]int)}
+			for _, x := range key {
+				if wing && x != h && x != -h {
+					o.layer = max(x, -x)
+				}
+			}
+			byKey[key] = o
+			t.orbits = append(t.orbits, o)
+		}
+		o.local[i] = len(o.positions)
+		o.positions = append(o.positions, i)
+	}
+
+	// Commutators of an inner layer with a conjugate of the right face or
+	// of another inner layer by the up face.  Those that cycle three pieces
+	// are the templates of their orbit, along with their rotations.
+	for _, a := range inner {
+		for _, e := range [...]Direction{Clock, Counterclock} {
+			ys := []Move{{Axis: Xax, Idx: h, Direction: Clock}}
+			for _, c := range inner {
+				if c != a {
+					ys = append(ys, Move{Axis: Xax, Idx: c, Direction: Clock})
+				}
+			}
+			for _, y := range ys {
+				alg := Commutator(Algorithm{{Axis: Xax, Idx: a, Direction: Clock}},
+					Algorithm{y}.Conjugate(Algorithm{{Axis: Zax, Idx: h, Direction: e}}))
+				moved := t.moved(alg, moveIndex)
+				if len(moved) != 3 {
+					continue
+				}
+				for _, o := range t.orbits {
+					if _, ok := o.local[moved[0]]; ok {
+						o.templates = append(o.templates, alg)
+					}
+				}
+			}
+		}
+	}
+	for _, o := range t.orbits {
+		o.init(t, moveIndex)
+	}
+	return t
+}
+
+// Returns the positions whose pieces an algorithm moves
+func (t *reductionTables) moved(alg Algorithm, moveIndex map[Move]int) []int {
+	var ret []int
+	for i := range t.positions {
+		j := int32(i)
+		for _, m := range alg {
+			j = t.perms[moveIndex[m]][j]
+		}
+		if int(j) != i {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func (o *reductionOrbit) init(t *reductionTables, moveIndex map[Move]int) {
+	rots := cubeRotations()
+	size := len(o.positions)
+	o.rotTo = make([][]uint8, size)
+	for p := range o.positions {
+		o.rotTo[p] = make([]uint8, size)
+		for r, rot := range rots {
+			q := rot.mult(cubi{pv: t.positions[o.positions[p]]}).pv
+			if j, ok := o.local[t.index[q]]; ok {
+				o.rotTo[p][j] = uint8(r)
+			}
+		}
+	}
+
+	for m, perm := range t.perms {
+		local := make([]uint8, size)
+		moves := false
+		for p, i := range o.positions {
+			local[p] = uint8(o.local[int(perm[i])])
+			moves = moves || int(perm[i]) != i
+		}
+		if moves {
+			o.gens = append(o.gens, m)
+			o.genPerms = append(o.genPerms, local)
+		}
+	}
+
+	// Every rotation of the templates, both ways, once per cycle
+	bases := o.templates
+	o.templates = nil
+	o.gen = make([]int16, size*size*size)
+	o.template = make([]int16, size*size*size)
+	for i := range o.gen {
+		o.gen[i] = reductionUnreachable
+	}
+	var queue []int
+	for _, base := range bases {
+		for _, rot := range rots {
+			for _, alg := range []Algorithm{base, base.Inverse()} {
+				rotated := make(Algorithm, len(alg))
+				for i, m := range alg {
+					rotated[i] = m.transform(rot)
+				}
+				moved := t.moved(rotated, moveIndex)
+				var cycle [3]int
+				cycle[0] = o.local[moved[0]]
+				for k := 1; k < 3; k++ {
+					j := t.positions[o.positions[cycle[k-1]]]
+					for _, m := range rotated {
+						j = t.positions[t.perms[moveIndex[m]][t.index[j]]]
+					}
+					cycle[k] = o.local[t.index[j]]
+				}
+				if o.gen[o.state(cycle[0], cycle[1], cycle[2])] != reductionUnreachable {
+					continue
+				}
+				for k := range 3 {
+					s := o.state(cycle[k], cycle[(k+1)%3], cycle[(k+2)%3])
+					o.gen[s] = reductionTemplate
+					o.template[s] = int16(len(o.templates))
+					queue = append(queue, s)
+				}
+				o.templates = append(o.templates, rotated)
+			}
+		}
+	}
+
+	// Breadth first search from the templates, backwards
+	inverse := make([][]uint8, len(o.gens))
+	for g, perm := range o.genPerms {
+		inverse[g] = make([]uint8, size)
+		for p, q := range perm {
+			inverse[g][q] = uint8(p)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		p1, p2, p3 := s/(size*size), s/size%size, s%size
+		for g, inv := range inverse {
+			prev := o.state(int(inv[p1]), int(inv[p2]), int(inv[p3]))
+			if o.gen[prev] == reductionUnreachable {
+				o.gen[prev] = int16(g)
+				queue = append(queue, prev)
+			}
+		}
+	}
+}
+
+func (o *reductionOrbit) state(p1, p2, p3 int) int {
+	size := len(o.positions)
+	return (p1*size+p2)*size + p3
+}
+
+// Returns an algorithm that takes the piece at p1 to p2, the one at p2 to
+// p3 and the one at p3 to p1, leaving the rest of the cube alone
+func (o *reductionOrbit) cycle(t *reductionTables, p1, p2, p3 int) (Algorithm, bool) {
+	s := o.state(p1, p2, p3)
+	var setup Algorithm
+	for o.gen[s] >= 0 {
+		g := o.gen[s]
+		setup = append(setup, t.moves[o.gens[g]])
+		perm := o.genPerms[g]
+		p1, p2, p3 = int(perm[p1]), int(perm[p2]), int(perm[p3])
+		s = o.state(p1, p2, p3)
+	}
+	if o.gen[s] == reductionUnreachable {
+		return nil, false
+	}
+	return o.templates[o.template[s]].Conjugate(setup), true
+}
+
+// The color of a center piece
+func sticker(cv cVec) Color {
+	return (cv[Xax] + cv[Yax] + cv[Zax]).Abs()
+}
+
+// The faces of a cube: its middle centers if it has them, or else its
+// corner at DBL
+func reductionFrame(cube Cube) (faceColors, bool) {
+	if cube.n%2 == 1 {
+		f := centerFaces(cube)
+		for _, side := range f {
+			if side[0] == zero || side[1] == zero {
+				return f, false
+			}
+		}
+		return f, true
+	}
+	return cornerFaces(cube)
+}
+
+// Returns the moves that solve a cube of size four or more, its faces
+// given by f
+func (s ReductionSolver) reduce(ctx context.Context, t *reductionTables, cube Cube, f faceColors, opts Options) ([]Move, error) {
+	n := cube.n
+	h := int(n / 2)
+	rots := cubeRotations()
+	var path []Move
+	apply := func(ms []Move) {
+		path = append(path, ms...)
+		cube = cube.MoveAll(ms)
+	}
+	colors := func() []cVec {
+		ret := make([]cVec, len(t.positions))
+		for _, cbi := range cube.cubis {
+			ret[t.index[cbi.pv]] = cbi.cv
+		}
+		return ret
+	}
+
+	// The colors a piece should have.  Edge pieces are paired with the
+	// middle edge on cubes of odd size.  On cubes of even size they go
+	// where they belong, but for UF and UB that are swapped when the
+	// corners are an odd permutation.
+	swap := false
+	if n%2 == 0 {
+		corners, ok := toCornerCubies(cube, f)
+		if !ok {
+			return nil, ErrNoPath
+		}
+		swap = permParity(corners.p[:]) == 1
+	}
+	target := func(cs []cVec, i int) cVec {
+		p := t.positions[i]
+		var ret cVec
+		for ax, x := range p {
+			if x == h {
+				ret[ax] = f[ax][1]
+			} else if x == -h {
+				ret[ax] = -f[ax][0]
+			}
+		}
+		if ret[Xax] != zero && ret[Yax] != zero && ret[Zax] != zero {
+			return ret
+		}
+		if n%2 == 1 && (ret[Xax] == zero) != (ret[Yax] == zero) != (ret[Zax] == zero) {
+			var middle vec
+			for ax, x := range p {
+				if x == h || x == -h {
+					middle[ax] = x
+				}
+			}
+			return cs[t.index[middle]]
+		}
+		if swap && p[Zax] == h && p[Yax] == h {
+			ret[Yax] = f[Yax][0]
+		} else if swap && p[Zax] == h && p[Yax] == -h {
+			ret[Yax] = -f[Yax][1]
+		}
+		return ret
+	}
+
+	// Where the edge pieces of an orbit go
+	homes := func(o *reductionOrbit) ([]uint8, bool) {
+		cs := colors()
+		ret := make([]uint8, len(o.positions))
+		used := make([]bool, len(o.positions))
+		for p, i := range o.positions {
+			found := false
+			for q, j := range o.positions {
+				rot := rots[o.rotTo[p][q]]
+				if !used[q] && rot.mult(cubi{cv: cs[i]}).cv == target(cs, j) {
+					ret[p] = uint8(q)
+					used[q] = true
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		}
+		return ret, true
+	}
+
+	// OLL parity
+	for _, o := range t.orbits {
+		if !o.wing {
+			continue
+		}
+		home, ok := homes(o)
+		if !ok {
+			return nil, ErrNoPath
+		}
+		if permParity(home) == 1 {
+			apply([]Move{{Axis: Xax, Idx: o.layer, Direction: Clock}})
+		}
+	}
+
+	// Centers, any piece of the right color will do
+	for _, o := range t.orbits {
+		if o.wing {
+			continue
+		}
+		for iter := 0; ; iter++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			cs := colors()
+			var have, want []Color
+			var wrong []int
+			for p, i := range o.positions {
+				have = append(have, sticker(cs[i]))
+				want = append(want, sticker(target(cs, i)))
+				if have[p] != want[p] {
+					wrong = append(wrong, p)
+				}
+			}
+			if len(wrong) == 0 {
+				break
+			}
+			if iter > len(o.positions) {
+				return nil, ErrNoPath
+			}
+			p1, p2, p3 := wrong[0], -1, -1
+			for _, p := range wrong {
+				if p2 < 0 && want[p] == have[p1] {
+					p2 = p
+				}
+			}
+			if p2 < 0 {
+				return nil, ErrNoPath
+			}
+			for _, p := range wrong {
+				if p3 < 0 && p != p1 && p != p2 && want[p] == have[p2] {
+					p3 = p
+				}
+			}
+			for p := range o.positions {
+				if p3 < 0 && p != p1 && p != p2 && want[p] == have[p2] && have[p] == have[p2] {
+					p3 = p
+				}
+			}
+			if p3 < 0 {
+				return nil, ErrNoPath
+			}
+			alg, ok := o.cycle(t, p1, p2, p3)
+			if !ok {
+				return nil, ErrNoPath
+			}
+			apply(alg)
+		}
+	}
+
+	// Edge pieces
+	for _, o := range t.orbits {
+		if !o.wing {
+			continue
+		}
+		for iter := 0; ; iter++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			home, ok := homes(o)
+			if !ok {
+				return nil, ErrNoPath
+			}
+			var wrong []int
+			for p, q := range home {
+				if int(q) != p {
+					wrong = append(wrong, p)
+				}
+			}
+			if len(wrong) == 0 {
+				break
+			}
+			if iter > 2*len(o.positions) {
+				return nil, ErrNoPath
+			}
+			p1 := wrong[0]
+			p2 := int(home[p1])
+			p3 := int(home[p2])
+			if p3 == p1 {
+				p3 = -1
+				for _, p := range wrong {
+					if p3 < 0 && p != p1 && p != p2 {
+						p3 = p
+					}
+				}
+				if p3 < 0 {
+					return nil, ErrNoPath
+				}
+			}
+			alg, ok := o.cycle(t, p1, p2, p3)
+			if !ok {
+				return nil, ErrNoPath
+			}
+			apply(alg)
+		}
+	}
+
+	// The reduced cube, its edges and centers made of one piece each
+	view := New(3)
+	cs := colors()
+	for i, cbi := range view.cubis {
+		var p vec
+		zeros := 0
+		for ax, x := range cbi.pv {
+			p[ax] = x * h
+			if x == 0 {
+				zeros++
+			}
+		}
+		switch {
+		case zeros == 1 && n%2 == 0:
+			for ax, x := range p {
+				if x == 0 {
+					p[ax] = 1
+				}
+			}
+			view.cubis[i].cv = cs[t.index[p]]
+		case zeros == 2 && n%2 == 0:
+			for ax, x := range p {
+				if x > 0 {
+					view.cubis[i].cv[ax] = f[ax][1]
+				} else if x < 0 {
+					view.cubis[i].cv[ax] = -f[ax][0]
+				}
+			}
+		default:
+			view.cubis[i].cv = cs[t.index[p]]
+		}
+	}
+	ms, err := s.Kociemba.Search(ctx, view, New(3), Options{Workers: opts.Workers, Progress: opts.Progress})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		m.Idx *= h
+		apply([]Move{m})
+	}
+	return path, nil
+}
+
+func (s ReductionSolver) GetPath(start Cube, end Cube) []Move {
+	path, err := s.Search(context.Background(), start, end, Options{})
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// Search ignores the options' MaxDepth and Metric, as the solver does not
+// look for short paths, but for cubes of size two and three.  Progress is
+// reported by the searches solving the reduced cubes.
+func (s ReductionSolver) Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error) {
+	if start.n != end.n {
+		return nil, ErrNoPath
+	}
+	switch start.n {
+	case 2:
+		return PocketSolver{}.Search(ctx, start, end, opts)
+	case 3:
+		return s.Kociemba.Search(ctx, start, end, opts)
+	}
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+	f, ok := reductionFrame(start)
+	if !ok {
+		return nil, ErrNoPath
+	}
+	t := getReductionTables(start.n)
+	path, err := s.reduce(ctx, t, start, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, rotated := range end.GetAllRotations() {
+		if g, ok := reductionFrame(rotated); ok && g == f {
+			back, err := s.reduce(ctx, t, rotated, f, opts)
+			if err != nil {
+				return nil, err
+			}
+			return append(path, Algorithm(back).Inverse()...), nil
+		}
+	}
+	return nil, ErrNoPath
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Direction of a turn, as seen when looking at the turning layer from
+// the positive end of the axis.
+type Direction bool
+
+const (
+	Clock        Direction = false
+	Counterclock Direction = true
+)
+
+func (dir Direction) String() string {
+	if dir {
+		return "counterclockwise"
+	}
+	return "clockwise"
+}
+
+// ParseDirection is the inverse of Direction.String
+func ParseDirection(str string) (Direction, error) {
+	switch str {
+	case "clockwise":
+		return Clock, nil
+	case "counterclockwise":
+		return Counterclock, nil
+	}
+	return Clock, fmt.Errorf("ParseDirection %s", str)
+}
+
+// Returns a 90 degree rotation matrix about an axis,
+// either counter-clockwise or clockwise
+func getRotationMatrix(a Axis, counter Direction) matrix {
+	var ret matrix
+	switch a {
+	case Xax:
+		if counter {
+			ret[0][0] = 1
+			ret[1][2] = 1
+			ret[2][1] = -1
+		} else {
+			ret[0][0] = 1
+			ret[1][2] = -1
+			ret[2][1] = 1
+		}
+	case Yax:
+		if counter {
+			ret[0][2] = -1
+			ret[1][1] = 1
+			ret[2][0] = 1
+		} else {
+			ret[0][2] = 1
+			ret[1][1] = 1
+			ret[2][0] = -1
+		}
+	case Zax:
+		if counter {
+			ret[0][1] = 1
+			ret[1][0] = -1
+			ret[2][2] = 1
+		} else {
+			ret[0][1] = -1
+			ret[1][0] = 1
+			ret[2][2] = 1
+		}
+	}
+	return ret
+}
+
+// Returns the move that turns the layer a move turns, once the whole cube
+// has been rotated by r
+func (m Move) transform(r matrix) Move {
+	for ax := range r {
+		s := r[ax][m.Axis] // the image of the axis, r times its unit vector
+		if s == 0 {
+			continue
+		}
+		dir := m.Direction
+		if s < 0 {
+			dir = !dir
+		}
+		return Move{Axis: Axis(ax), Idx: s * m.Idx, Direction: dir}
+	}
+	return m
+}
+
+// Multiplies two matrices, a after b
+func (a matrix) times(b matrix) matrix {
+	var ret matrix
+	for i := range ret {
+		for j := range ret[i] {
+			for k := range b {
+				ret[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return ret
+}
+
+// The 24 rotations of the whole cube, the identity first
+var cubeRotations = sync.OnceValue(func() []matrix {
+	ret := []matrix{{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}}
+	seen := map[matrix]bool{ret[0]: true}
+	for i := 0; i < len(ret); i++ {
+		for _, ax := range [...]Axis{Xax, Zax} {
+			next := getRotationMatrix(ax, Clock).times(ret[i])
+			if !seen[next] {
+				seen[next] = true
+				ret = append(ret, next)
+			}
+		}
+	}
+	return ret
+})
@@ -0,0 +1,262 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// ColorMode tells how a terminal renderer colors its output
+type ColorMode int
+
+const (
+	TrueColor ColorMode = iota // 24-bit ANSI colors
+	ANSI256                    // the 256 ANSI colors
+	NoColor                    // color letters, without escape codes
+	ANSI16                     // the 16 ANSI colors, where orange is bright red
+)
+
+// ColorScheme tells how the faces of a solved cube are colored, and how
+// the colors are displayed.  Besides the standard schemes returned by
+// Western and Japanese, any arrangement of the six colors can be used.
+//
+// The zero ColorScheme is not a valid arrangement of colors.  Renderers
+// take it to mean the Western scheme.
+type ColorScheme struct {
+	// Faces is the color of each face of a solved cube that has not been
+	// rotated, in the order of facelet strings: U, R, F, D, L and B
+	Faces [6]Color
+	// Palette is the color each color is displayed in.  Colors left zero
+	// are displayed as in the standard schemes.
+	Palette [Blue + 1]color.RGBA
+}
+
+// The colors of a real cube
+var standardPalette = [Blue + 1]color.RGBA{
+	Green:  {0x00, 0x9b, 0x48, 0xff},
+	White:  {0xff, 0xff, 0xff, 0xff},
+	Orange: {0xff, 0x58, 0x00, 0xff},
+	Red:    {0xb7, 0x12, 0x34, 0xff},
+	Yellow: {0xff, 0xd5, 0x00, 0xff},
+	Blue:   {0x00, 0x46, 0xad, 0xff},
+}
+
+// Western returns the color scheme of most cubes, and of New and Reset:
+// yellow opposite white, green opposite blue and orange opposite red, with
+// yellow up, orange right and green in front
+func Western() ColorScheme {
+	return ColorScheme{
+		Faces:   [6]Color{Yellow, Orange, Green, White, Red, Blue},
+		Palette: standardPalette,
+	}
+}
+
+// Japanese returns the Japanese color scheme: yellow opposite green, white
+// opposite blue and orange opposite red, with yellow up, orange right and
+// white in front
+func Japanese() ColorScheme {
+	return ColorScheme{
+		Faces:   [6]Color{Yellow, Orange, White, Green, Red, Blue},
+		Palette: standardPalette,
+	}
+}
+
+// The scheme renderers use for s: Western for the zero scheme
+func (s ColorScheme) orWestern() ColorScheme {
+	if s == (ColorScheme{}) {
+		return Western()
+	}
+	return s
+}
+
+// Tells whether the faces have each of the six colors once
+func (s ColorScheme) valid() bool {
+	var seen [Blue + 1]bool
+	for _, c := range s.Faces {
+		if c < Green || c > Blue || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+// The colors of the faces along each axis, negative side first
+func (s ColorScheme) faceColors() faceColors {
+	var f faceColors
+	for i, c := range s.Faces {
+		pv, ax := faceletPlace(3, i, 1, 1)
+		if pv[ax] > 0 {
+			f[ax][1] = c
+		} else {
+			f[ax][0] = c
+		}
+	}
+	return f
+}
+
+// New returns a solved cube of size n colored with the scheme.  It panics
+// if n is not larger than one or if the faces do not have each color once.
+func (s ColorScheme) New(n uint) Cube {
+	if n <= 1 {
+		panic("n must be greater than 1")
+	}
+	ret := Cube{n: n}
+	ret.cubis = make([]cubi, int(math.Pow(float64(n), 3)-math.Pow(float64(n)-2, 3)))
+	s.Reset(&ret)
+	return ret
+}
+
+// Reset solves the cube, coloring it with the scheme.  It panics if the
+// faces do not have each color once.
+func (s ColorScheme) Reset(cube *Cube) {
+	if !s.valid() {
+		panic(fmt.Sprintf("invalid color scheme %v", s.Faces))
+	}
+	f := s.faceColors()
+	n := cube.n
+	ncubi := 0
+	for x := -int(n) / 2; x <= int(n)/2; x++ {
+		if x == 0 && n%2 == 0 {
+			continue
+		}
+		for y := -int(n) / 2; y <= int(n)/2; y++ {
+			if y == 0 && n%2 == 0 {
+				continue
+			}
+			for z := -int(n) / 2; z <= int(n)/2; z++ {
+				if z == 0 && n%2 == 0 {
+					continue
+				}
+
+				// Determine whether it's a center cubi (not an exterior-facing cubi)
+				if x < int(n)/2 && x > -int(n)/2 &&
+					y < int(n)/2 && y > -int(n)/2 &&
+					z < int(n)/2 && z > -int(n)/2 {
+					continue
+				}
+
+				// Give the cubi a color on each axis it has a face on,
+				// negative on the negative side
+				pv := vec{x, y, z}
+				var cv cVec
+				for ax, p := range pv {
+					if p == int(n/2) {
+						cv[ax] = f[ax][1]
+					} else if p == -int(n/2) {
+						cv[ax] = -f[ax][0]
+					}
+				}
+				cube.cubis[ncubi] = cubi{cv: cv, pv: pv}
+				ncubi += 1
+			}
+		}
+	}
+}
+
+// IsCanonical tells whether the centers of an odd sized cube are where
+// Reset puts them with the scheme
+func (s ColorScheme) IsCanonical(cube Cube) bool {
+	if cube.n%2 == 0 { // Only odd sized cubes can be canonical
+		return false
+	}
+	return centerFaces(cube) == s.faceColors()
+}
+
+var schemeFaces sync.Map // faces of a scheme to the faces of its rotations
+
+// The faces of the 24 rotations of a cube solved with the scheme
+func (s ColorScheme) solvedFaces() map[faceColors]bool {
+	if t, ok := schemeFaces.Load(s.Faces); ok {
+		return t.(map[faceColors]bool)
+	}
+	t := make(map[faceColors]bool)
+	for _, rotated := range s.New(3).GetAllRotations() {
+		t[centerFaces(rotated)] = true
+	}
+	actual, _ := schemeFaces.LoadOrStore(s.Faces, t)
+	return actual.(map[faceColors]bool)
+}
+
+// The color a color is displayed in, black for no color
+func (s ColorScheme) rgb(c Color) color.RGBA {
+	if c < Green || c > Blue {
+		return color.RGBA{0, 0, 0, 0xff}
+	}
+	if s.Palette[c] == (color.RGBA{}) {
+		return standardPalette[c]
+	}
+	return s.Palette[c]
+}
+
+// The color a color is displayed in, as in SVG
+func (s ColorScheme) hex(c Color) string {
+	rgb := s.rgb(c)
+	return fmt.Sprintf("#%02x%02x%02x", rgb.R, rgb.G, rgb.B)
+}
+
+// The ANSI escape code that sets the foreground, or the background, to
+// the color a color is displayed in.  It is empty with NoColor.
+func (s ColorScheme) ansi(c Color, mode ColorMode, background bool) string {
+	rgb := s.rgb(c)
+	switch mode {
+	case NoColor:
+		return ""
+	case ANSI16:
+		code := ansi16(rgb)
+		if code >= 8 {
+			code += 90 - 8
+		} else {
+			code += 30
+		}
+		if background {
+			code += 10
+		}
+		return fmt.Sprintf("\033[%dm", code)
+	}
+	code := 38
+	if background {
+		code = 48
+	}
+	if mode == ANSI256 {
+		return fmt.Sprintf("\033[%d;5;%dm", code, ansi256(rgb))
+	}
+	return fmt.Sprintf("\033[%d;2;%d;%d;%dm", code, rgb.R, rgb.G, rgb.B)
+}
+
+// The 16 ANSI colors, as xterm shows them
+var ansi16Colors = [16]color.RGBA{
+	{0, 0, 0, 0xff}, {205, 0, 0, 0xff}, {0, 205, 0, 0xff}, {205, 205, 0, 0xff},
+	{0, 0, 238, 0xff}, {205, 0, 205, 0xff}, {0, 205, 205, 0xff}, {229, 229, 229, 0xff},
+	{127, 127, 127, 0xff}, {255, 0, 0, 0xff}, {0, 255, 0, 0xff}, {255, 255, 0, 0xff},
+	{92, 92, 255, 0xff}, {255, 0, 255, 0xff}, {0, 255, 255, 0xff}, {255, 255, 255, 0xff},
+}
+
+// The closest of the 16 ANSI colors.  Orange has none close, and comes
+// out bright red.
+func ansi16(c color.RGBA) int {
+	best, bestDist := 0, -1
+	for i, a := range ansi16Colors {
+		dr, dg, db := int(c.R)-int(a.R), int(c.G)-int(a.G), int(c.B)-int(a.B)
+		if dist := dr*dr + dg*dg + db*db; bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// The closest of the 256 ANSI colors, in the 6x6x6 color cube
+func ansi256(c color.RGBA) int {
+	level := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		return min((int(v)-35)/40, 5)
+	}
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// A Solver finds a list of moves that takes the start cube to the end cube.
+type Solver interface {
+	GetPath(start Cube, end Cube) []Move
+}
+
+// A SearchSolver is a Solver whose search can be cancelled, bounded and
+// watched.  Its GetPath is Search with a background context and the
+// options given by the solver's fields, returning nil on any error.
+//
+// Search returns the context's error if it is done before a path is
+// found, ErrNoPath if there is no path, or none within the options'
+// MaxDepth, and ErrMetric if the solver cannot measure paths in the
+// options' Metric.
+type SearchSolver interface {
+	Solver
+	Search(ctx context.Context, start Cube, end Cube, opts Options) ([]Move, error)
+}
+
+// Options bound a search
+type Options struct {
+	// MaxDepth is the longest path looked for, in Metric.  Zero means
+	// the solver's default.
+	MaxDepth int
+
+	// Metric is the metric MaxDepth and the depths reported to Progress
+	// are measured in
+	Metric Metric
+
+	// Timeout bounds the time spent searching, on top of the deadline of
+	// the context.  Zero means no bound.
+	Timeout time.Duration
+
+	// Workers is the number of goroutines searching in parallel.  Zero
+	// means one per CPU.  KociembaSolver and BidirectionalSolver search
+	// in parallel, and ReductionSolver in its last stage.  PocketSolver
+	// walks down a table and LayerSolver follows stages, and both ignore
+	// it.
+	Workers int
+
+	// Progress, if not nil, is called from the goroutine that called
+	// Search as the search deepens.  Every SearchSolver of this package
+	// reports progress as it goes, and stops when the context is done;
+	// their Search methods tell what a depth is to them.
+	Progress func(Progress)
+}
+
+// Progress tells how far a search got
+type Progress struct {
+	Depth int   // the depth being searched
+	Nodes int64 // the states visited so far
+}
+
+var (
+	// ErrNoPath is returned when there is no path between two cubes
+	ErrNoPath = errors.New("cube: no path between the cubes")
+	// ErrMetric is returned when a solver does not support a metric
+	ErrMetric = errors.New("cube: metric not supported")
+)
+
+// Returns a context that is done after the options' timeout, if any
+func (opts Options) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if opts.Timeout > 0 {
+		return context.WithTimeout(ctx, opts.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (opts Options) workers() int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func (opts Options) progress(depth int, nodes int64) {
+	if opts.Progress != nil {
+		opts.Progress(Progress{Depth: depth, Nodes: nodes})
+	}
+}
+
+// Metric is the way the length of a solution is measured
+type Metric int
+
+const (
+	// HTM, the half turn metric, counts half turns as one move
+	HTM Metric = iota
+	// QTM, the quarter turn metric, counts half turns as two moves
+	QTM
+)
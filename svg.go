@@ -0,0 +1,93 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SVGOptions tells how to draw a Flat as SVG.  The zero value draws
+// stickers of DefaultStickerSize pixels with no gap between them, in the
+// colors of the Western scheme.
+type SVGOptions struct {
+	// StickerSize is the width of a sticker in pixels, DefaultStickerSize
+	// if zero
+	StickerSize int
+	// Gap is the space between stickers in pixels.  Sides are twice as far
+	// apart.
+	Gap int
+	// Scheme is the colors stickers are drawn in, the Western scheme if
+	// zero
+	Scheme ColorScheme
+	// Highlight outlines the stickers at the given row and column of the
+	// Flat
+	Highlight map[[2]int]bool
+	// Labels writes text on the stickers at the given row and column of
+	// the Flat
+	Labels map[[2]int]string
+}
+
+// DefaultStickerSize is the width of a sticker, in pixels, when
+// SVGOptions does not give one
+const DefaultStickerSize = 30
+
+// WriteSVG draws the Flat as an SVG image of the same net String prints:
+// up, then left, front, right and back, then down.  It fails if a sticker
+// is not a color.
+func (fl Flat) WriteSVG(w io.Writer, opts SVGOptions) error {
+	l := fl.netLayout(opts.StickerSize, opts.Gap)
+	width, height := l.width(), l.height()
+	scheme := opts.Scheme.orWestern()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	var highlighted [][2]int
+	for r := range fl {
+		for c, s := range fl[r] {
+			if s == "" || s == " " {
+				continue
+			}
+			color, err := ParseColor(s)
+			if err != nil {
+				return fmt.Errorf("flat: unknown color %q at row %d, column %d", s, r, c)
+			}
+			x, y := l.x(c), l.y(r)
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#000000"/>`+"\n", x, y, l.size, l.size, scheme.hex(color))
+			if label, ok := opts.Labels[[2]int{r, c}]; ok {
+				fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" font-family="sans-serif" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n",
+					x+l.size/2, y+l.size/2, max(l.size/2, 1), escapeXML(label))
+			}
+			if opts.Highlight[[2]int{r, c}] {
+				highlighted = append(highlighted, [2]int{r, c})
+			}
+		}
+	}
+
+	// Outlines go on top, so that neighbors do not cover them
+	stroke := max(l.size/10, 2)
+	for _, rc := range highlighted {
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#ff00ff" stroke-width="%d"/>`+"\n",
+			l.x(rc[1]), l.y(rc[0]), l.size, l.size, stroke)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// WriteSVG draws the cube opened up as a Flat.  See Flat.WriteSVG.
+func (cube Cube) WriteSVG(w io.Writer, opts SVGOptions) error {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl.WriteSVG(w, opts)
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
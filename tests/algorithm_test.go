@@ -0,0 +1,152 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func mustParse(t *testing.T, n uint, str string) Algorithm {
+	t.Helper()
+	a, err := ParseAlgorithm(n, str)
+	if err != nil {
+		t.Fatalf("%q: %v", str, err)
+	}
+	return a
+}
+
+func TestAlgorithmInverse(t *testing.T) {
+	for _, n := range []uint{2, 3, 4, 5} {
+		a := Algorithm(randomMoves(n, 50))
+		if !New(n).MoveAll(a.Concat(a.Inverse())).IsSolved() {
+			t.Errorf("a a' is not the identity! n=%d", n)
+		}
+		if !New(n).MoveAll(a.Inverse().Concat(a)).IsSolved() {
+			t.Errorf("a' a is not the identity! n=%d", n)
+		}
+	}
+}
+
+func TestAlgorithmRepeat(t *testing.T) {
+	for str, order := range map[string]int{
+		"R U R' U'":        6,
+		"R U R' U R U2 R'": 6,
+		"R":                4,
+		"R U":              105,
+	} {
+		a := mustParse(t, 3, str)
+		for k := 1; k < order; k++ {
+			if New(3).MoveAll(a.Repeat(k)).IsSolved() {
+				t.Errorf("%q repeated %d times should not be solved", str, k)
+			}
+		}
+		if !New(3).MoveAll(a.Repeat(order)).IsSolved() {
+			t.Errorf("%q repeated %d times should be solved", str, order)
+		}
+		if !New(3).MoveAll(a.Concat(a.Repeat(-1))).IsSolved() {
+			t.Errorf("Repeat(-1) of %q is not its inverse", str)
+		}
+	}
+}
+
+func TestCommutatorConjugate(t *testing.T) {
+	r, u, f := mustParse(t, 3, "R"), mustParse(t, 3, "U"), mustParse(t, 3, "F")
+	if got := Commutator(r, u).Format(3); got != "R U R' U'" {
+		t.Errorf("[R, U] is %q", got)
+	}
+	if got := Commutator(r, u).Conjugate(f).Format(3); got != "F R U R' U' F'" {
+		t.Errorf("[F: [R, U]] is %q", got)
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, tc := range []struct {
+		n        uint
+		str      string
+		expected string
+	}{
+		{3, "[R, U]", "R U R' U'"},
+		{3, "[F: [R, U]]", "F R U R' U' F'"},
+		{3, "[R U R', D]", "R U R' D R U' R' D'"},
+		{3, "(R U R' U')3", "R U R' U' R U R' U' R U R' U'"},
+		{3, "(R U)'", "U' R'"},
+		{3, "[R, U]2'", "U R U' R' U R U' R'"},
+		{4, "[Rw: (U2)2]", "Rw U2 U2 Rw'"},
+		{3, "", ""},
+	} {
+		a := mustParse(t, tc.n, tc.str)
+		other, err := ParseMoves(tc.n, tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(a) != len(other) || !New(tc.n).MoveAll(a).Equal(New(tc.n).MoveAll(other)) {
+			t.Errorf("%q differs from %q", tc.str, tc.expected)
+		}
+	}
+}
+
+func TestParseAlgorithmErrors(t *testing.T) {
+	for _, tc := range []struct {
+		str string
+		pos int
+	}{
+		{"[R, U", 0},
+		{"R [R U]", 6},
+		{"(R U", 0},
+		{"R U)", 3},
+		{"(R U]", 4},
+		{"[R, U) ", 5},
+		{"[R, Q]", 4},
+		{"(R)99999999999999999", 3},
+		{"(R)99999999999999999999", 3},
+		{"((R U)500000)1000000", 13},
+		{strings.Repeat("[", 20) + "R, U]" + strings.Repeat(", U]", 19), 1},
+		{"(R)1048576 [R, U]", 12},
+		{"(R)1048576 U", 11},
+		{"(R)1048575 (U)2", 14},
+	} {
+		_, err := ParseAlgorithm(3, tc.str)
+		var nerr *NotationError
+		if !errors.As(err, &nerr) {
+			t.Errorf("expected a NotationError for %q, got %v", tc.str, err)
+			continue
+		}
+		if nerr.Pos != tc.pos {
+			t.Errorf("%q: expected an error at %d, got %d (%v)", tc.str, tc.pos, nerr.Pos, err)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	for _, tc := range []struct {
+		str      string
+		expected string
+	}{
+		{"R R", "R2"},
+		{"R R'", ""},
+		{"R U U' R'", ""},
+		{"R R R", "R'"},
+		{"R2 R2", ""},
+		{"R L R'", "L"},
+		{"F R U R' U' F' F U R U' R' F'", ""},
+		{"R U2 U2 R", "R2"},
+		{"U R R' U D", "U2 D"},
+	} {
+		a := mustParse(t, 3, tc.str).Simplify()
+		if got := a.Format(3); got != tc.expected {
+			t.Errorf("%q simplified to %q, expected %q", tc.str, got, tc.expected)
+		}
+	}
+	for _, n := range []uint{2, 3, 4, 5} {
+		a := Algorithm(randomMoves(n, 200))
+		if !New(n).MoveAll(a).Equal(New(n).MoveAll(a.Simplify())) {
+			t.Errorf("simplifying changed the algorithm! n=%d", n)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestBidirectionalSolver(t *testing.T) {
+	for _, tc := range []struct {
+		n     uint
+		moves string
+	}{
+		{2, "R U F' R2"},
+		{3, "R U F' D2 L"},
+		{3, "R M U' E"},
+		{4, "2R U Fw' D"},
+		{5, "3R U 2F'"},
+	} {
+		ms, err := ParseMoves(tc.n, tc.moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := New(tc.n)
+		start.Shuffle(30)
+		end := start.MoveAll(ms).Rotate(Xax, Clock).Rotate(Zax, Counterclock)
+		path := BidirectionalSolver{}.GetPath(start, end)
+		if path == nil {
+			t.Fatalf("no path found for %s!", tc.moves)
+		}
+		if !reaches(start, path, end) {
+			t.Errorf("path does not reach the end cube! %v", path)
+		}
+		if l, max := pathLength(path, HTM), pathLength(ms, HTM); l > max {
+			t.Errorf("path of length %d for %s, expected at most %d", l, tc.moves, max)
+		}
+	}
+}
+
+func TestBidirectionalSolverOptimal(t *testing.T) {
+	for _, metric := range []Metric{HTM, QTM} {
+		for range 5 {
+			cube := New(2)
+			cube.Shuffle(6)
+			want := PocketSolver{Metric: metric}.GetPath(cube, New(2))
+			got := BidirectionalSolver{Metric: metric}.GetPath(cube, New(2))
+			if got == nil {
+				t.Fatal("no path found!")
+			}
+			if !cube.MoveAll(got).IsSolved() {
+				t.Errorf("path does not solve the cube! %v", got)
+			}
+			if pathLength(got, metric) != pathLength(want, metric) {
+				t.Errorf("path of length %d, expected %d", pathLength(got, metric), pathLength(want, metric))
+			}
+		}
+	}
+}
+
+func TestBidirectionalSolverMaxLength(t *testing.T) {
+	ms, err := ParseMoves(3, "R U F D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3).MoveAll(ms)
+	if path := (BidirectionalSolver{MaxLength: 3}).GetPath(cube, New(3)); path != nil {
+		t.Errorf("expected no path of length 3, got %v", path)
+	}
+	if path := (BidirectionalSolver{}).GetPath(New(3), New(4)); path != nil {
+		t.Errorf("expected no path between cubes of different sizes, got %v", path)
+	}
+	if path := (BidirectionalSolver{}).GetPath(cube, cube.Rotate(Yax, Clock)); path == nil || len(path) != 0 {
+		t.Errorf("expected an empty path between rotations of a cube, got %v", path)
+	}
+}
+
+func TestBidirectionalSolverWorkers(t *testing.T) {
+	for range 3 {
+		cube := New(3)
+		cube.Shuffle(5)
+		want := BidirectionalSolver{Workers: 1}.GetPath(cube, New(3))
+		if want == nil {
+			t.Fatal("no path found!")
+		}
+		for _, workers := range []int{2, 8} {
+			got := BidirectionalSolver{Workers: workers}.GetPath(cube, New(3))
+			if FormatMoves(3, got) != FormatMoves(3, want) {
+				t.Errorf("%d workers found %s, one worker found %s", workers, FormatMoves(3, got), FormatMoves(3, want))
+			}
+		}
+	}
+}
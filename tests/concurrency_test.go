@@ -0,0 +1,129 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+// These tests are meant to be run with the race detector, as in
+// go test -race ./tests -run Concurrent
+
+// Goroutines share a cube and move it, each its own way.  The shared cube
+// must not change, and each goroutine must end where it would alone.
+func TestConcurrentMoves(t *testing.T) {
+	shared := New(4)
+	shared.Shuffle(20)
+	before := shared.Copy()
+	ms, err := ParseMoves(4, "R U2 r' F Uw B' D L2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := shared.MoveAll(ms)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			cube := shared
+			switch g % 4 {
+			case 0:
+				cube = cube.MoveAll(ms)
+			case 1:
+				for _, m := range ms {
+					cube = cube.Move(m)
+				}
+			case 2:
+				cube = cube.Copy()
+				cube.ApplyAll(ms)
+			case 3:
+				cube = cube.Permute(Compile(4, ms))
+			}
+			if !cube.Equal(want) {
+				t.Errorf("goroutine %d did not end at the expected cube", g)
+			}
+			_ = shared.GetAllRotations()
+			_ = shared.IsSolved()
+			_ = shared.Validate()
+		})
+	}
+	wg.Wait()
+	if !shared.Equal(before) {
+		t.Errorf("the shared cube changed")
+	}
+}
+
+// Goroutines build the tables of a size for the first time together
+func TestConcurrentNewSize(t *testing.T) {
+	const n = 13
+	var wg sync.WaitGroup
+	cubes := make([]Cube, 8)
+	for g := range cubes {
+		wg.Go(func() {
+			cube := New(n)
+			cube.Apply(Move{Axis: Axis(g % 3), Idx: g%7 - 3, Direction: Clock})
+			cube = cube.Permute(Compile(n, []Move{{Axis: Zax, Idx: 6, Direction: Counterclock}}))
+			cubes[g] = cube
+		})
+	}
+	wg.Wait()
+	for g, cube := range cubes {
+		want := New(n).MoveAll([]Move{
+			{Axis: Axis(g % 3), Idx: g%7 - 3, Direction: Clock},
+			{Axis: Zax, Idx: 6, Direction: Counterclock},
+		})
+		if !cube.Equal(want) {
+			t.Errorf("goroutine %d did not end at the expected cube", g)
+		}
+	}
+}
+
+// Goroutines render the same cube with different options at once, and
+// each gets what it would alone
+func TestConcurrentRendering(t *testing.T) {
+	cube := New(3)
+	cube.Shuffle(20)
+	modes := []ColorMode{TrueColor, ANSI256, NoColor, ANSI16}
+	schemes := []ColorScheme{Western(), Japanese()}
+	render := func(i int) string {
+		opts := TextOptions{Mode: modes[i%len(modes)], Scheme: schemes[i%len(schemes)]}
+		var b strings.Builder
+		b.WriteString(cube.String())
+		b.WriteString(cube.Text(opts))
+		pic, err := cube.Isometric(IsometricOptions{Mode: opts.Mode, Scheme: opts.Scheme, View: "y"})
+		if err != nil {
+			t.Error(err)
+		}
+		b.WriteString(pic)
+		var svg bytes.Buffer
+		if err := cube.WriteSVG(&svg, SVGOptions{Scheme: opts.Scheme}); err != nil {
+			t.Error(err)
+		}
+		b.Write(svg.Bytes())
+		return b.String()
+	}
+	want := make([]string, 8)
+	for i := range want {
+		want[i] = render(i)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 32 {
+		wg.Go(func() {
+			if got := render(g); got != want[g%len(want)] {
+				t.Errorf("goroutine %d rendered differently than alone", g)
+			}
+			var png bytes.Buffer
+			if err := cube.WritePNG(&png, ImageOptions{Scheme: schemes[g%len(schemes)]}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+}
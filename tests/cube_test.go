@@ -5,8 +5,9 @@
 package cube_test
 
 import (
-	. "github.com/dfava/cube/internal"
+	. "github.com/dfava/cube"
 
+	"iter"
 	"math/rand"
 	"testing"
 )
@@ -65,8 +66,171 @@ func TestTurn(t *testing.T) {
 			final = final.Move(Move{Axis: perms[num_perms].ax, Idx: perms[num_perms].idx, Direction: !perms[num_perms].dir})
 		}
 		// Make sure init and final are the same
-		if cube.String() != final.String() {
+		if !cube.Equal(final) {
 			t.Errorf("turns failed! n=%d", n)
 		}
 	}
 }
+
+func TestMoves(t *testing.T) {
+	for _, n := range []uint{2, 3, 4, 5} {
+		ms := randomMoves(n, 20)
+		cube := New(n)
+		cubes := cube.Moves(ms)
+		if len(cubes) != len(ms) {
+			t.Fatalf("expected %d cubes, got %d", len(ms), len(cubes))
+		}
+		curr := cube
+		for i, m := range ms {
+			curr = curr.Move(m)
+			if !cubes[i].Equal(curr) {
+				t.Errorf("wrong cube after move %d! n=%d", i, n)
+			}
+		}
+		if !cube.MoveAll(ms).Equal(curr) {
+			t.Errorf("MoveAll does not match Moves! n=%d", n)
+		}
+		if !cube.Equal(New(n)) {
+			t.Errorf("moves changed the original cube! n=%d", n)
+		}
+	}
+}
+
+func TestStates(t *testing.T) {
+	ms := randomMoves(3, 20)
+	cube := New(3)
+	var i int
+	for state := range cube.States(ms) {
+		if !state.Equal(cube.MoveAll(ms[:i+1])) {
+			t.Errorf("wrong state after move %d", i)
+		}
+		i++
+		if i == 10 {
+			break
+		}
+	}
+	if i != 10 {
+		t.Errorf("expected to stop after 10 states, got %d", i)
+	}
+
+	// Sequences start over from the cube each time they are ranged over
+	for name, states := range map[string]func([]Move) iter.Seq[Cube]{
+		"States":     cube.States,
+		"TurnStates": cube.TurnStates,
+	} {
+		seq := states(ms)
+		var first, second []Cube
+		for state := range seq {
+			first = append(first, state)
+		}
+		for state := range seq {
+			second = append(second, state)
+		}
+		if len(first) != len(ms) || len(second) != len(ms) {
+			t.Fatalf("%s: %d and %d states for %d moves", name, len(first), len(second), len(ms))
+		}
+		for k := range first {
+			if !first[k].Equal(second[k]) {
+				t.Errorf("%s: state %d differs the second time", name, k)
+			}
+		}
+	}
+}
+
+func TestTurnCanonical(t *testing.T) {
+	for _, n := range []uint{3, 5, 7} {
+		ms := randomMoves(n, 50)
+		cube := New(n)
+		if !cube.TurnAll(ms).IsCanonical() {
+			t.Errorf("TurnAll did not preserve the orientation! n=%d", n)
+		}
+		for state := range cube.TurnStates(ms) {
+			if !state.IsCanonical() {
+				t.Errorf("TurnStates did not preserve the orientation! n=%d", n)
+				break
+			}
+		}
+		// Turning the middle layer is the same as turning the outer layers the other way
+		mid := cube.Turn(Move{Axis: Xax, Idx: 0, Direction: Clock})
+		var outer []Move
+		for idx := -int(n) / 2; idx <= int(n)/2; idx++ {
+			if idx != 0 {
+				outer = append(outer, Move{Axis: Xax, Idx: idx, Direction: Counterclock})
+			}
+		}
+		if !mid.Equal(cube.MoveAll(outer)) {
+			t.Errorf("turning the middle layer is not the same as turning the outer layers! n=%d", n)
+		}
+	}
+}
+
+func TestBigCube(t *testing.T) {
+	n := uint(60)
+	var ms []Move
+	for range 200 {
+		ms = append(ms, Move{Axis: Axis(rand.Intn(3)), Idx: rand.Intn(int(n)) - int(n)/2, Direction: Direction(rand.Intn(2) == 1)})
+	}
+	cube := New(n).MoveAll(ms)
+	if cube.Equal(New(n)) {
+		t.Errorf("cube unchanged after %d moves", len(ms))
+	}
+	if !cube.MoveAll(Algorithm(ms).Inverse()).Equal(New(n)) {
+		t.Errorf("moves undone do not give back the solved cube")
+	}
+}
+
+func benchmarkMove(b *testing.B, n uint) {
+	cube := New(n)
+	m := Move{Axis: Xax, Idx: 1, Direction: Clock}
+	for b.Loop() {
+		cube = cube.Move(m)
+	}
+}
+
+func BenchmarkMove3(b *testing.B)  { benchmarkMove(b, 3) }
+func BenchmarkMove10(b *testing.B) { benchmarkMove(b, 10) }
+func BenchmarkMove50(b *testing.B) { benchmarkMove(b, 50) }
+
+func TestApply(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		cube := New(n)
+		cube.Shuffle(20)
+		want := cube
+		for range 50 {
+			m := Move{Axis: Axis(rand.Intn(3)), Idx: rand.Intn(int(n)) - int(n)/2, Direction: Direction(rand.Intn(2) == 1)}
+			want = want.Move(m)
+			cube.Apply(m)
+			if !cube.Equal(want) {
+				t.Fatalf("Apply(%v) differs from Move on a cube of size %d", m, n)
+			}
+		}
+		ms := []Move{{Axis: Xax, Idx: 1, Direction: Clock}, {Axis: Zax, Idx: -1, Direction: Counterclock}}
+		before := cube.MoveAll(ms)
+		cube.ApplyAll(ms)
+		if !cube.Equal(before) {
+			t.Errorf("ApplyAll differs from MoveAll on a cube of size %d", n)
+		}
+	}
+}
+
+func TestApplyAllocs(t *testing.T) {
+	cube := New(5)
+	ms := []Move{{Axis: Xax, Idx: 2, Direction: Clock}, {Axis: Yax, Idx: 0, Direction: Counterclock}}
+	cube.ApplyAll(ms) // builds the tables
+	if allocs := testing.AllocsPerRun(100, func() { cube.ApplyAll(ms) }); allocs != 0 {
+		t.Errorf("%v allocations per ApplyAll, expected none", allocs)
+	}
+}
+
+func benchmarkApply(b *testing.B, n uint) {
+	cube := New(n)
+	m := Move{Axis: Xax, Idx: 1, Direction: Clock}
+	b.ReportAllocs()
+	for b.Loop() {
+		cube.Apply(m)
+	}
+}
+
+func BenchmarkApply3(b *testing.B)  { benchmarkApply(b, 3) }
+func BenchmarkApply10(b *testing.B) { benchmarkApply(b, 10) }
+func BenchmarkApply50(b *testing.B) { benchmarkApply(b, 50) }
@@ -0,0 +1,246 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+// A cuboid as long along every axis moves and prints like a cube
+func TestCuboidCube(t *testing.T) {
+	for n := uint(2); n <= 5; n++ {
+		cube := New(n)
+		cuboid := NewCuboid(n, n, n)
+		if cuboid.String() != cube.String() {
+			t.Errorf("cuboid of %d^3 prints as\n%s\nexpected\n%s", n, cuboid, cube)
+		}
+		for i := range 40 {
+			legal := cuboid.LegalMoves()
+			if len(legal) != 6*int(n) {
+				t.Fatalf("%d legal moves on a cuboid of %d^3", len(legal), n)
+			}
+			m := legal[(7*i)%len(legal)]
+			cube, cuboid = cube.Move(m), cuboid.Move(m)
+			if cuboid.String() != cube.String() {
+				t.Fatalf("after %s, cuboid of %d^3 is\n%s\nexpected\n%s", m, n, cuboid, cube)
+			}
+		}
+	}
+}
+
+func TestCuboidSolved(t *testing.T) {
+	cuboid := NewCuboid(2, 2, 3)
+	want := strings.Join([]string{
+		"      y y             ",
+		"      y y             ",
+		"r r | g g | o o | b b ",
+		"r r | g g | o o | b b ",
+		"r r | g g | o o | b b ",
+		"      w w             ",
+		"      w w             ",
+	}, "\r\n")
+	if got := cuboid.String(); got != want {
+		t.Errorf("2x2x3 prints as\n%q\nexpected\n%q", got, want)
+	}
+	if x, y, z := cuboid.Size(); x != 2 || y != 2 || z != 3 {
+		t.Errorf("size %dx%dx%d, expected 2x2x3", x, y, z)
+	}
+	if !cuboid.IsSolved() {
+		t.Errorf("new cuboid is not solved")
+	}
+}
+
+func TestCuboidTurns(t *testing.T) {
+	for _, tc := range []struct {
+		x, y, z uint
+		quarter [3]bool
+		legal   int
+	}{
+		{2, 2, 3, [3]bool{false, false, true}, 2 + 2 + 3*2},
+		{3, 3, 4, [3]bool{false, false, true}, 3 + 3 + 4*2},
+		{1, 2, 3, [3]bool{false, false, false}, 1 + 2 + 3},
+		{2, 3, 3, [3]bool{true, false, false}, 2*2 + 3 + 3},
+		{1, 1, 3, [3]bool{false, false, true}, 1 + 1 + 3*2},
+	} {
+		cuboid := NewCuboid(tc.x, tc.y, tc.z)
+		for _, ax := range []Axis{Xax, Yax, Zax} {
+			if cuboid.QuarterTurns(ax) != tc.quarter[ax] {
+				t.Errorf("%dx%dx%d: quarter turns along %s is %v", tc.x, tc.y, tc.z, ax, !tc.quarter[ax])
+			}
+		}
+		legal := cuboid.LegalMoves()
+		if len(legal) != tc.legal {
+			t.Errorf("%dx%dx%d: %d legal moves, expected %d", tc.x, tc.y, tc.z, len(legal), tc.legal)
+		}
+		for _, m := range legal {
+			moved := cuboid.Move(m)
+			if moved.Equal(cuboid) {
+				t.Errorf("%dx%dx%d: %s does not move", tc.x, tc.y, tc.z, m)
+			}
+			// Every move comes back after four quarter turns, or two half
+			// turns in either direction
+			back := moved.Move(m)
+			if cuboid.QuarterTurns(m.Axis) {
+				back = back.Move(m).Move(m)
+			} else if !moved.Move(Move{Axis: m.Axis, Idx: m.Idx, Direction: !m.Direction}).Equal(cuboid) {
+				t.Errorf("%dx%dx%d: %s is not a half turn", tc.x, tc.y, tc.z, m)
+			}
+			if !back.Equal(cuboid) {
+				t.Errorf("%dx%dx%d: %s does not come back", tc.x, tc.y, tc.z, m)
+			}
+		}
+	}
+}
+
+// A thin cuboid keeps a sticker on each side of its thin cubis
+func TestCuboidThin(t *testing.T) {
+	cuboid := NewCuboid(1, 2, 3)
+	before := cuboid.String()
+	moved := cuboid.Move(Move{Axis: Zax, Idx: 1, Direction: Clock})
+	if moved.IsSolved() {
+		t.Errorf("1x2x3 is solved after turning the top layer")
+	}
+	// The layer turns over, taking the orange stickers on the right to the
+	// left and the blue one at the back to the front
+	lines := strings.Split(moved.String(), "\r\n")
+	if got, want := lines[2], "o o | b | r r | g "; got != want {
+		t.Errorf("top row of the sides is %q, expected %q\n%s", got, want, moved)
+	}
+	if got := moved.Move(Move{Axis: Zax, Idx: 1, Direction: Clock}).String(); got != before {
+		t.Errorf("turning the top layer twice gives\n%s", got)
+	}
+	// Turning the whole cuboid over keeps it solved
+	whole := cuboid.Move(Move{Axis: Xax, Idx: 0, Direction: Clock})
+	if !whole.IsSolved() || whole.Equal(cuboid) {
+		t.Errorf("turning the 1x2x3 over gives\n%s", whole)
+	}
+}
+
+func TestCuboidShuffle(t *testing.T) {
+	for _, size := range [][3]uint{{2, 2, 3}, {3, 3, 4}, {1, 2, 3}} {
+		cuboid := NewCuboid(size[0], size[1], size[2])
+		cuboid.Shuffle(30)
+		if size[0] > 1 && cuboid.IsSolved() {
+			t.Errorf("%v: solved after shuffling", size)
+		}
+		// The layers along z of a 2x2x3 and a 3x3x4 take quarter turns,
+		// so the sides of the middle band are mixed, but those along x and
+		// y only take half turns, so the top and the bottom only ever hold
+		// their own colors
+		if size[0] == size[1] && size[0] > 1 {
+			lines := strings.Split(cuboid.String(), "\r\n")
+			for r := range int(size[1]) {
+				for _, line := range []string{lines[r], lines[len(lines)-1-r]} {
+					if strings.ContainsAny(line, "gorb") {
+						t.Errorf("%v: side color on the top or bottom after shuffling\n%s", size, cuboid)
+					}
+				}
+			}
+		}
+		// A shuffled cuboid has the same number of stickers of each color
+		// as a solved one
+		count := func(c Cuboid) map[rune]int {
+			ret := make(map[rune]int)
+			for _, r := range c.String() {
+				if strings.ContainsRune("gworyb", r) {
+					ret[r]++
+				}
+			}
+			return ret
+		}
+		solved := NewCuboid(size[0], size[1], size[2])
+		got, want := count(cuboid), count(solved)
+		for _, r := range "gworyb" {
+			if got[r] != want[r] {
+				t.Errorf("%v: %d stickers of %c after shuffling, expected %d", size, got[r], r, want[r])
+			}
+		}
+	}
+}
+
+func TestCuboidText(t *testing.T) {
+	cuboid := Japanese().NewCuboid(2, 2, 3)
+	if got := cuboid.Text(TextOptions{Mode: NoColor}); got != cuboid.String() {
+		t.Errorf("text without colors differs from String:\n%s", got)
+	}
+	if text := cuboid.Text(TextOptions{}); !strings.Contains(text, "\033[38;2;255;88;0mo\033[0m") {
+		t.Errorf("expected orange in 24-bit colors:\n%s", text)
+	}
+	if strings.Split(cuboid.String(), "\r\n")[2][6] != 'w' {
+		t.Errorf("expected white in front of a Japanese cuboid:\n%s", cuboid)
+	}
+}
+
+// The methods of Flat work on the Flat of a cuboid
+func TestCuboidFlat(t *testing.T) {
+	for _, size := range [][3]uint{{2, 2, 3}, {3, 3, 4}, {1, 2, 3}, {3, 1, 4}, {2, 2, 2}} {
+		for _, scheme := range []ColorScheme{Western(), Japanese()} {
+			cuboid := scheme.NewCuboid(size[0], size[1], size[2])
+			cuboid.Shuffle(20)
+			var fl Flat
+			fl.PaintCuboid(cuboid)
+
+			if got := fl.String(); got != cuboid.String() {
+				t.Errorf("%v: Flat prints as\n%s\nexpected\n%s", size, got, cuboid)
+			}
+			if got := fl.Copy().String(); got != cuboid.String() {
+				t.Errorf("%v: copy prints as\n%s", size, got)
+			}
+			if text, err := fl.MarshalText(); err != nil || string(text) != cuboid.String() {
+				t.Errorf("%v: MarshalText gives %q, %v", size, text, err)
+			}
+			back, err := fl.Cuboid()
+			if err != nil {
+				t.Errorf("%v: %v", size, err)
+			} else if !back.Equal(cuboid) {
+				t.Errorf("%v: Flat reads back as\n%s\nexpected\n%s", size, back, cuboid)
+			}
+			cube := fl.Cube()
+			if size[0] == size[1] && size[1] == size[2] {
+				if cube.GetSize() != size[0] {
+					t.Errorf("%v: Cube gives a cube of size %d", size, cube.GetSize())
+				}
+			} else if cube.GetSize() != 0 {
+				t.Errorf("%v: Cube gives a cube of size %d, expected the zero Cube", size, cube.GetSize())
+			}
+		}
+	}
+
+	var fl Flat
+	fl.PaintCuboid(NewCuboid(2, 2, 3))
+	var buf bytes.Buffer
+	if err := fl.WriteSVG(&buf, SVGOptions{StickerSize: 10, Gap: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// 8 stickers of 10, 7 gaps of 1 between them and 3 more between sides,
+	// and a margin of 2 on each side
+	if !strings.Contains(buf.String(), `width="94" height="82"`) {
+		t.Errorf("unexpected size of the net:\n%s", buf.String())
+	}
+	if got := strings.Count(buf.String(), "<rect"); got != 2*(2*2+2*3+2*3) {
+		t.Errorf("%d stickers drawn", got)
+	}
+	img, err := fl.Image(ImageOptions{StickerSize: 10, Gap: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 94 || b.Dy() != 82 {
+		t.Errorf("image of %dx%d, expected 94x82", b.Dx(), b.Dy())
+	}
+
+	fl[0][0] = "y"
+	if _, err := fl.Cuboid(); err == nil {
+		t.Errorf("expected an error for a sticker outside the sides")
+	}
+	fl.PaintCuboid(NewCuboid(2, 2, 3))
+	fl[3][3] = "q"
+	if _, err := fl.Cuboid(); err == nil {
+		t.Errorf("expected an error for an unknown color")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestBinaryEncoding(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		cube := New(n)
+		cube.Shuffle(50)
+		data, err := cube.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 + (18*int(n)*int(n)+7)/8; len(data) != want {
+			t.Errorf("encoding of %d bytes for a cube of size %d, expected %d", len(data), n, want)
+		}
+		var other Cube
+		if err := other.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !cube.Equal(other) || cube.String() != other.String() {
+			t.Errorf("decoded cube differs!\n%s\n%s", cube, other)
+		}
+		if cube.Hash() != other.Hash() || cube.Key() != other.Key() {
+			t.Errorf("decoded cube hashes differently")
+		}
+	}
+}
+
+func TestBinaryEncodingInvalid(t *testing.T) {
+	data, err := New(3).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A visible sticker with no color
+	noColor := append([]byte(nil), data...)
+	noColor[1] &^= 0x07
+	// A sticker past the last one, in the bits that pad the last byte
+	padded := append([]byte(nil), data...)
+	padded[len(padded)-1] |= 0x80
+	for name, bad := range map[string][]byte{"zero color": noColor, "padding": padded} {
+		var cube Cube
+		if err := cube.UnmarshalBinary(bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	cube := New(3)
+	cube.Shuffle(50)
+
+	// A cube read from its flat has its pieces in another order
+	var fl Flat
+	fl.PaintCube(cube)
+	if !cube.Equal(fl.Cube()) || cube.Hash() != fl.Cube().Hash() {
+		t.Errorf("cubes that look the same are not equal")
+	}
+	turned := cube.Move(Move{Axis: Xax, Idx: 1, Direction: Clock})
+	if cube.Equal(turned) || cube.Hash() == turned.Hash() || cube.Key() == turned.Key() {
+		t.Errorf("cubes that look different are equal")
+	}
+	if cube.Equal(cube.Rotate(Zax, Clock)) {
+		t.Errorf("a cube equals its rotation")
+	}
+	if New(2).Equal(New(3)) {
+		t.Errorf("cubes of different sizes are equal")
+	}
+}
+
+func TestKey(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, rotated := range New(3).GetAllRotations() {
+		seen[rotated.Key()] = true
+	}
+	if len(seen) != 24 {
+		t.Errorf("%d keys for the rotations of a cube, expected 24", len(seen))
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	data, err := New(3).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := append([]byte{}, data...)
+	bad[1] |= 7 // the first sticker, of color 7
+	for _, data := range [][]byte{nil, {1}, data[:len(data)-1], append(data, 0), bad} {
+		var cube Cube
+		if err := cube.UnmarshalBinary(data); err == nil {
+			t.Errorf("expected an error decoding %v", data)
+		}
+	}
+}
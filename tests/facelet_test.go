@@ -0,0 +1,127 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestFacelets(t *testing.T) {
+	for _, tc := range []struct {
+		moves    string
+		facelets string
+	}{
+		{"", "UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBB"},
+		{"R", "UUFUUFUUFRRRRRRRRRFFDFFDFFDDDBDDBDDBLLLLLLLLLUBBUBBUBB"},
+		{"U", "UUUUUUUUUBBBRRRRRRRRRFFFFFFDDDDDDDDDFFFLLLLLLLLLBBBBBB"},
+		{"F", "UUUUUULLLURRURRURRFFFFFFFFFRRRDDDDDDLLDLLDLLDBBBBBBBBB"},
+	} {
+		ms, err := ParseMoves(3, tc.moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cube := New(3).MoveAll(ms)
+		if got, err := cube.Facelets(); err != nil || got != tc.facelets {
+			t.Errorf("facelets after %q:\n got %s\nwant %s", tc.moves, got, tc.facelets)
+		}
+		other, err := ParseFacelets(tc.facelets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !other.Equal(cube) {
+			t.Errorf("parsed facelets of %q differ from the cube", tc.moves)
+		}
+	}
+}
+
+func TestFaceletsRoundTrip(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		cube := New(n)
+		cube.Shuffle(50)
+		s, err := cube.Facelets()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s) != 6*int(n*n) {
+			t.Errorf("facelet string of length %d for a cube of size %d", len(s), n)
+		}
+		other, err := ParseFacelets(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !other.Equal(cube) {
+			t.Errorf("parsed facelets differ from the cube of size %d", n)
+		}
+	}
+}
+
+func facelets(t *testing.T, cube Cube) string {
+	t.Helper()
+	s, err := cube.Facelets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Letters stand for the faces of the scheme the cube is colored with
+func TestFaceletsScheme(t *testing.T) {
+	ms, err := ParseMoves(3, "R U' x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scheme := range []ColorScheme{Western(), Japanese(), {Faces: [6]Color{Red, Yellow, Blue, Orange, White, Green}}} {
+		if s, err := scheme.Facelets(scheme.New(3)); err != nil || s != facelets(t, New(3)) {
+			t.Errorf("facelets of a solved cube in %v are %s, %v", scheme.Faces, s, err)
+		}
+		cube := scheme.New(3).MoveAll(ms)
+		s, err := scheme.Facelets(cube)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := facelets(t, New(3).MoveAll(ms)); s != want {
+			t.Errorf("facelets in %v are %s, expected %s", scheme.Faces, s, want)
+		}
+		other, err := scheme.ParseFacelets(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !other.Equal(cube) {
+			t.Errorf("parsed facelets in %v differ from the cube", scheme.Faces)
+		}
+	}
+
+	if _, err := New(3).Facelets(); err != nil {
+		t.Error(err)
+	}
+	if _, err := (ColorScheme{}).Facelets(New(3)); err == nil {
+		t.Errorf("expected an error for an invalid scheme")
+	}
+	// A cube with a sticker missing, as read from an incomplete Flat
+	var fl Flat
+	fl.PaintCube(New(3))
+	fl[0][3] = " "
+	if s, err := fl.Cube().Facelets(); err == nil {
+		t.Errorf("expected an error for a missing sticker, got %s", s)
+	}
+}
+
+func TestParseFaceletsErrors(t *testing.T) {
+	solved := facelets(t, New(3))
+	for _, s := range []string{
+		"",
+		"UUUUUU",
+		solved[1:],
+		solved[:10] + "X" + solved[11:],
+		strings.ToLower(solved),
+	} {
+		if _, err := ParseFacelets(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
@@ -5,16 +5,20 @@
 package cube_test
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 
-	. "github.com/dfava/cube/internal"
+	. "github.com/dfava/cube"
 
 	"testing"
 )
 
 func TestFromFile2(t *testing.T) {
 	var fl Flat
-	fl.FromFile("cube2.txt")
+	if err := fl.FromFile("cube2.txt"); err != nil {
+		t.Fatal(err)
+	}
 	fmt.Println(fl)
 	cube := fl.Cube()
 	fmt.Println()
@@ -23,7 +27,9 @@ func TestFromFile2(t *testing.T) {
 
 func TestFromFile3(t *testing.T) {
 	var fl Flat
-	fl.FromFile("cube3.txt")
+	if err := fl.FromFile("cube3.txt"); err != nil {
+		t.Fatal(err)
+	}
 	fmt.Println(fl)
 	cube := fl.Cube()
 	fmt.Println()
@@ -40,7 +46,7 @@ func cube2Flat2Cube(t *testing.T, sizes []uint) {
 			fmt.Println(fl)
 			var other = fl.Cube()
 			fmt.Println(other)
-			if cube.String() != other.String() {
+			if !cube.Equal(other) {
 				t.Errorf("flattening and reconstructing failed! n=%d", n)
 			}
 		}
@@ -54,3 +60,85 @@ func TestCube2Flat2CubeOdd(t *testing.T) {
 func TestCube2Flat2CubeEven(t *testing.T) {
 	cube2Flat2Cube(t, []uint{2, 4, 6, 8})
 }
+
+func TestFromString(t *testing.T) {
+	for n := uint(2); n <= 6; n++ {
+		cube := New(n)
+		cube.Shuffle(20)
+		var fl, other Flat
+		fl.PaintCube(cube)
+		if err := other.FromString(fl.String()); err != nil {
+			t.Errorf("reading a printed cube of size %d: %v", n, err)
+			continue
+		}
+		if !other.Cube().Equal(cube) {
+			t.Errorf("reading a printed cube of size %d gave another cube", n)
+		}
+	}
+}
+
+func TestFromStringErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		text string
+		want FlatError
+	}{
+		{"unknown color", `
+      y y
+      y y
+r r | g x | o o | b b
+r r | g g | o o | b b
+      w w
+      w w`, FlatError{Line: 4, Col: 9}},
+		{"ragged row", `
+      y y
+      y y
+r r | g g | o o | b b
+r r | g g | o o | b
+      w w
+      w w`, FlatError{Line: 5}},
+		{"too few rows", `
+      y y
+      y y
+r r | g g | o o | b b
+r r | g g | o o | b b
+      w w`, FlatError{Line: 7}},
+		{"too many rows", `
+      y y
+      y y
+r r | g g | o o | b b
+r r | g g | o o | b b
+      w w
+      w w
+      w w`, FlatError{Line: 8}},
+		{"sides not square", `
+      y y
+r r | g g | o o | b b
+r r | g g | o o | b b
+      w w
+      w w`, FlatError{Line: 3}},
+		{"size one", "y", FlatError{Line: 1}},
+		{"empty", "", FlatError{Line: 1}},
+	} {
+		var fl Flat
+		err := fl.FromString(tc.text)
+		ferr, ok := err.(*FlatError)
+		if !ok {
+			t.Errorf("%s: expected a *FlatError, got %v", tc.name, err)
+			continue
+		}
+		if ferr.Line != tc.want.Line || ferr.Col != tc.want.Col {
+			t.Errorf("%s: expected line %d column %d, got %v", tc.name, tc.want.Line, tc.want.Col, err)
+		}
+		if fl != nil {
+			t.Errorf("%s: the flat was changed", tc.name)
+		}
+	}
+}
+
+func TestFromFileMissing(t *testing.T) {
+	var fl Flat
+	if err := fl.FromFile("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/dfava/cube"
+)
+
+func flatOf(cube Cube) Flat {
+	var fl Flat
+	fl.PaintCube(cube)
+	return fl
+}
+
+func TestMoveFrames(t *testing.T) {
+	for n := uint(2); n <= 5; n++ {
+		cube := New(n)
+		cube.Shuffle(10)
+		for _, m := range randomMoves(n, 5) {
+			frames := cube.MoveFrames(m, 4)
+			if len(frames) != 5 {
+				t.Fatalf("%d frames, expected 5", len(frames))
+			}
+			if frames[0].String() != flatOf(cube).String() {
+				t.Errorf("first frame is not the cube of size %d", n)
+			}
+			if frames[4].String() != flatOf(cube.Move(m)).String() {
+				t.Errorf("last frame is not the cube of size %d after %v", n, m)
+			}
+
+			turned := cube.TurnFrames(m, 4)
+			if turned[4].String() != flatOf(cube.Turn(m)).String() {
+				t.Errorf("last frame is not the cube of size %d after turning %v", n, m)
+			}
+			// Stickers that do not move are shown all along, unless moving
+			// stickers slide over them
+			for from, to := range cube.GetFlatPermutation(m.Axis, m.Idx, m.Direction) {
+				for _, fl := range turned {
+					if from == to && fl[from[0]][from[1]] == " " {
+						t.Errorf("sticker at %v of a cube of size %d hidden while turning %v", from, n, m)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	cube := New(3)
+	var buf bytes.Buffer
+	scheme := Western()
+	scheme.Palette[Yellow] = color.RGBA{1, 2, 3, 255}
+	opts := ImageOptions{StickerSize: 10, Gap: 1, Scheme: scheme, Background: color.White}
+	if err := cube.WritePNG(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12 stickers of 10, 11 gaps of 1 between them and 3 more between
+	// sides, and a margin of 2 on each side
+	if b := img.Bounds(); b.Dx() != 138 || b.Dy() != 104 {
+		t.Errorf("image of %dx%d, expected 138x104", b.Dx(), b.Dy())
+	}
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{0, 0, color.White},
+		{2 + 3*11 + 1 + 5, 2 + 5, color.RGBA{1, 2, 3, 255}},                     // up
+		{2 + 3*11 + 1 + 5, 2 + 3*11 + 1 + 5, color.RGBA{0x00, 0x9b, 0x48, 255}}, // front
+		{2 + 3*11 + 1, 2 + 3*11 + 1 + 5, color.Black},                           // an outline
+	} {
+		r1, g1, b1, a1 := img.At(tc.x, tc.y).RGBA()
+		r2, g2, b2, a2 := tc.want.RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			t.Errorf("color at (%d, %d) is %v, expected %v", tc.x, tc.y, img.At(tc.x, tc.y), tc.want)
+		}
+	}
+
+	var fl Flat
+	fl.PaintCube(cube)
+	fl[0][3] = "x"
+	if err := fl.WritePNG(&buf, opts); err == nil {
+		t.Errorf("expected an error for an unknown color")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	cube := New(3)
+	ms, err := ParseMoves(3, "R U' M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	opts := GIFOptions{ImageOptions: ImageOptions{StickerSize: 6}, Steps: 4, Delay: 50 * time.Millisecond}
+	if err := cube.WriteGIF(&buf, ms, opts); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 1+3*4 {
+		t.Fatalf("%d frames, expected %d", len(anim.Image), 1+3*4)
+	}
+	want := []int{25, 5, 5, 5, 25, 5, 5, 5, 25, 5, 5, 5, 25}
+	if !slices.Equal(anim.Delay, want) {
+		t.Errorf("delays %v, expected %v", anim.Delay, want)
+	}
+	last, err := cube.MoveAll(ms).Image(opts.ImageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(anim.Image[len(anim.Image)-1].Pix, last.Pix) {
+		t.Errorf("last frame is not the cube after the moves")
+	}
+}
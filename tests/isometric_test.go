@@ -0,0 +1,85 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/dfava/cube"
+)
+
+// The color letters in a picture
+func letters(pic string) string {
+	var ret []byte
+	for _, l := range "gworyb" {
+		if strings.ContainsRune(pic, l) {
+			ret = append(ret, byte(l))
+		}
+	}
+	return string(ret)
+}
+
+func TestIsometric(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		for _, tc := range []struct {
+			view    string
+			letters string // visible colors, in the order of "gworyb"
+		}{
+			{"", "goy"},
+			{"x2", "wob"},
+			{"y", "oyb"},
+			{"z'", "gwo"},
+		} {
+			pic, err := New(n).Isometric(IsometricOptions{View: tc.view, Mode: NoColor, Scale: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := letters(pic); got != tc.letters {
+				t.Errorf("colors %q seen on a cube of size %d viewed with %q, expected %q", got, n, tc.view, tc.letters)
+			}
+			lines := strings.Split(pic, "\r\n")
+			if len(lines) != 3*int(n) {
+				t.Errorf("%d lines for a cube of size %d, expected %d", len(lines), n, 3*n)
+			}
+			for _, l := range lines {
+				if utf8.RuneCountInString(l) != utf8.RuneCountInString(lines[0]) {
+					t.Errorf("lines of different widths for a cube of size %d", n)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestIsometricColors(t *testing.T) {
+	cube := New(3)
+	scheme := Western()
+	scheme.Palette[Yellow] = color.RGBA{1, 2, 3, 255}
+	pic, err := cube.Isometric(IsometricOptions{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(pic, "\033[38;2;1;2;3m") || !strings.Contains(pic, "\033[38;2;0;155;72m") || !strings.Contains(pic, "▀") {
+		t.Errorf("expected 24-bit colors and half blocks:\n%s", pic)
+	}
+	pic, err = cube.Isometric(IsometricOptions{Mode: ANSI256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(pic, "\033[38;5;") || strings.Contains(pic, "\033[38;2;") {
+		t.Errorf("expected 256 colors:\n%s", pic)
+	}
+}
+
+func TestIsometricBadView(t *testing.T) {
+	for _, view := range []string{"R", "x R", "Q"} {
+		if _, err := New(3).Isometric(IsometricOptions{View: view}); err == nil {
+			t.Errorf("expected an error for view %q", view)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/dfava/cube"
+)
+
+func TestKociembaSolver(t *testing.T) {
+	solver := KociembaSolver{}
+	for range 20 {
+		cube := New(3)
+		cube.Shuffle(50)
+		path := solver.GetPath(cube, New(3))
+		if path == nil {
+			t.Fatalf("no path found!\n%s", cube)
+		}
+		if !cube.MoveAll(path).IsSolved() {
+			t.Errorf("path does not solve the cube! %v\n%s", path, cube)
+		}
+		if l := pathLength(path, HTM); l > DefaultMaxLength {
+			t.Errorf("path of length %d, expected at most %d", l, DefaultMaxLength)
+		}
+	}
+}
+
+func TestKociembaSolverEnd(t *testing.T) {
+	solver := KociembaSolver{}
+	for range 5 {
+		start, end := New(3), New(3)
+		start.Shuffle(30)
+		end.Shuffle(30)
+		end = end.Rotate(Yax, Clock)
+		path := solver.GetPath(start, end)
+		if path == nil {
+			t.Fatal("no path found!")
+		}
+		if !reaches(start, path, end) {
+			t.Errorf("path does not reach the end cube! %v", path)
+		}
+	}
+}
+
+func TestKociembaSolverMaxLength(t *testing.T) {
+	ms, err := ParseMoves(3, "R U2 F'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3).MoveAll(ms)
+	if path := (KociembaSolver{MaxLength: 2}).GetPath(cube, New(3)); path != nil {
+		t.Errorf("expected no path of length 2, got %v", path)
+	}
+	path := KociembaSolver{MaxLength: 3}.GetPath(cube, New(3))
+	if path == nil || !cube.MoveAll(path).IsSolved() {
+		t.Errorf("expected a path of length 3, got %v", path)
+	}
+	if path := (KociembaSolver{}).GetPath(New(3), New(3)); path == nil || len(path) != 0 {
+		t.Errorf("expected an empty path, got %v", path)
+	}
+}
+
+func TestKociembaSolverTimeout(t *testing.T) {
+	solver := KociembaSolver{MaxLength: 16, Timeout: 10 * time.Millisecond}
+	if err := solver.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3)
+	cube.Shuffle(50)
+	begin := time.Now()
+	path := solver.GetPath(cube, New(3))
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("search took %s despite a timeout of %s", elapsed, solver.Timeout)
+	}
+	if path != nil && !cube.MoveAll(path).IsSolved() {
+		t.Errorf("path does not solve the cube! %v", path)
+	}
+}
+
+func TestKociembaSolverInvalid(t *testing.T) {
+	solver := KociembaSolver{}
+	if path := solver.GetPath(New(2), New(2)); path != nil {
+		t.Errorf("expected no path for a 2x2x2 cube, got %v", path)
+	}
+
+	// Flip the edge at UF
+	var fl Flat
+	fl.PaintCube(New(3))
+	fl[2][4], fl[3][4] = fl[3][4], fl[2][4]
+	flipped := fl.Cube()
+	if path := solver.GetPath(flipped, New(3)); path != nil {
+		t.Errorf("expected no path for a flipped edge, got %v", path)
+	}
+}
+
+func TestKociembaSolverCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := (KociembaSolver{CacheDir: dir}).Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, "kociemba.tables"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Solvers with a different directory read the tables from disk
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "kociemba.tables"), buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3)
+	cube.Shuffle(50)
+	path := KociembaSolver{CacheDir: other}.GetPath(cube, New(3))
+	if path == nil || !cube.MoveAll(path).IsSolved() {
+		t.Errorf("path read from cached tables does not solve the cube! %v", path)
+	}
+
+	// and rebuild the tables if the file is corrupt
+	corrupt := t.TempDir()
+	if err := os.WriteFile(filepath.Join(corrupt, "kociemba.tables"), buf[:len(buf)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (KociembaSolver{CacheDir: corrupt}).Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := os.ReadFile(filepath.Join(corrupt, "kociemba.tables"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rebuilt) != len(buf) {
+		t.Errorf("expected the corrupt table file to be rewritten")
+	}
+}
+
+func TestKociembaSolverCacheErrors(t *testing.T) {
+	// A file where the directory should be
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	solver := KociembaSolver{CacheDir: filepath.Join(file, "cache")}
+	if err := solver.Prepare(); err == nil {
+		t.Errorf("expected an error storing the tables under a file")
+	}
+	cube := New(3)
+	cube.Shuffle(50)
+	if _, err := solver.Search(context.Background(), cube, New(3), Options{}); err == nil || errors.Is(err, ErrNoPath) {
+		t.Errorf("expected the error storing the tables, got %v", err)
+	}
+	if path := solver.GetPath(cube, New(3)); path != nil {
+		t.Errorf("expected no path without tables, got %v", path)
+	}
+
+	// Building the tables stops when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := KociembaSolver{CacheDir: t.TempDir()}.Search(ctx, cube, New(3), Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v building the tables, got %v", context.Canceled, err)
+	}
+}
+
+// The solver's Timeout and Workers apply when the options leave them out
+func TestKociembaSolverSearchDefaults(t *testing.T) {
+	solver := KociembaSolver{Timeout: 10 * time.Millisecond, Workers: 2}
+	if err := solver.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3)
+	cube.Shuffle(50)
+	begin := time.Now()
+	_, err := solver.Search(context.Background(), cube, New(3), Options{MaxDepth: 16})
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("search took %s despite the solver's timeout", elapsed)
+	}
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrNoPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestKociembaSolverWorkers(t *testing.T) {
+	for range 5 {
+		cube := New(3)
+		cube.Shuffle(50)
+		want := KociembaSolver{Workers: 1}.GetPath(cube, New(3))
+		if want == nil {
+			t.Fatal("no path found!")
+		}
+		for _, workers := range []int{2, 8} {
+			got := KociembaSolver{Workers: workers}.GetPath(cube, New(3))
+			if FormatMoves(3, got) != FormatMoves(3, want) {
+				t.Errorf("%d workers found %s, one worker found %s", workers, FormatMoves(3, got), FormatMoves(3, want))
+			}
+		}
+	}
+}
+
+func benchmarkKociembaSolver(b *testing.B, workers int) {
+	solver := KociembaSolver{MaxLength: 20, Workers: workers}
+	if err := solver.Prepare(); err != nil {
+		b.Fatal(err)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	cubes := make([]Cube, 16)
+	for i := range cubes {
+		cubes[i] = New(3)
+		for range 50 {
+			cubes[i] = cubes[i].Move(Move{Axis: Axis(rng.IntN(3)), Idx: rng.IntN(3) - 1, Direction: Direction(rng.IntN(2) == 1)})
+		}
+	}
+	for i := 0; b.Loop(); i++ {
+		solver.GetPath(cubes[i%len(cubes)], New(3))
+	}
+}
+
+func BenchmarkKociembaSolver1(b *testing.B) { benchmarkKociembaSolver(b, 1) }
+
+func BenchmarkKociembaSolverN(b *testing.B) { benchmarkKociembaSolver(b, runtime.GOMAXPROCS(0)) }
@@ -0,0 +1,123 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+// Stickers of a flattened 3x3x3 cube that match their center once a stage
+// of the layer by layer method is done
+var layerStickers = [...][][2]int{
+	{{6, 4}, {7, 3}, {7, 5}, {8, 4}, {5, 1}, {5, 4}, {5, 7}, {5, 10}},
+	{{6, 3}, {6, 5}, {8, 3}, {8, 5}, {5, 0}, {5, 2}, {5, 3}, {5, 5}, {5, 6}, {5, 8}, {5, 9}, {5, 11}},
+	{{4, 0}, {4, 2}, {4, 3}, {4, 5}, {4, 6}, {4, 8}, {4, 9}, {4, 11}},
+	{{0, 4}, {1, 3}, {1, 5}, {2, 4}},
+	{{0, 3}, {0, 5}, {2, 3}, {2, 5}},
+}
+
+func TestLayerSolver(t *testing.T) {
+	solver := LayerSolver{}
+	for range 10 {
+		cube := New(3)
+		cube.Shuffle(50)
+		stages := solver.Stages(cube, New(3))
+		if len(stages) != 6 {
+			t.Fatalf("expected 6 stages, got %d", len(stages))
+		}
+		var want [][2]int
+		for i, stage := range stages {
+			if stage.Name == "" || stage.Explanation == "" {
+				t.Errorf("stage %d has no name or explanation", i)
+			}
+			cube = cube.MoveAll(stage.Moves)
+			if i < len(layerStickers) {
+				want = append(want, layerStickers[i]...)
+			}
+			var fl Flat
+			fl.PaintCube(cube)
+			for _, rc := range want {
+				if center := fl[rc[0]/3*3+1][rc[1]/3*3+1]; fl[rc[0]][rc[1]] != center {
+					t.Errorf("stage %q: sticker %v is %s, expected %s\n%s", stage.Name, rc, fl[rc[0]][rc[1]], center, fl)
+				}
+			}
+		}
+		if !cube.IsSolved() {
+			t.Errorf("stages do not solve the cube!\n%s", cube)
+		}
+	}
+}
+
+func TestLayerSolverEnd(t *testing.T) {
+	solver := LayerSolver{}
+	for range 5 {
+		start, end := New(3), New(3)
+		start.Shuffle(30)
+		end.Shuffle(30)
+		end = end.Rotate(Zax, Counterclock)
+		path := solver.GetPath(start, end)
+		if path == nil {
+			t.Fatal("no path found!")
+		}
+		if !reaches(start, path, end) {
+			t.Errorf("path does not reach the end cube! %v", path)
+		}
+	}
+}
+
+func TestLayerSolverSolved(t *testing.T) {
+	stages := LayerSolver{}.Stages(New(3), New(3))
+	if len(stages) != 6 {
+		t.Fatalf("expected 6 stages, got %d", len(stages))
+	}
+	for _, stage := range stages {
+		if len(stage.Moves) != 0 {
+			t.Errorf("stage %q of a solved cube has moves %v", stage.Name, stage.Moves)
+		}
+	}
+	if path := (LayerSolver{}).GetPath(New(2), New(2)); path != nil {
+		t.Errorf("expected no path for a 2x2x2 cube, got %v", path)
+	}
+}
+
+func TestLayerSolverProgress(t *testing.T) {
+	cube := New(3)
+	cube.Shuffle(50)
+	var reports []Progress
+	_, err := LayerSolver{}.Search(context.Background(), cube, New(3), Options{
+		Progress: func(p Progress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 6 {
+		t.Fatalf("expected progress at each of the 6 stages, got %+v", reports)
+	}
+	for i, p := range reports {
+		if p.Depth != i || (i > 0 && p.Nodes < reports[i-1].Nodes) {
+			t.Errorf("progress %d is %+v, after %+v", i, p, reports[max(i-1, 0)])
+		}
+	}
+	if _, err := (LayerSolver{}).Search(context.Background(), New(2), New(2), Options{}); err != ErrNoPath {
+		t.Errorf("expected %v for a 2x2x2 cube, got %v", ErrNoPath, err)
+	}
+}
+
+// The explanation of the second layer names the inserts the solver plays
+func TestLayerSolverInserts(t *testing.T) {
+	stages := LayerSolver{}.Stages(New(3), New(3))
+	if len(stages) != 6 {
+		t.Fatalf("expected 6 stages, got %d", len(stages))
+	}
+	for _, insert := range []string{"U R U' R' U' F' U F", "U' F' U F U R U' R'"} {
+		if !strings.Contains(stages[2].Explanation, insert) {
+			t.Errorf("stage %q does not explain %s:\n%s", stages[2].Name, insert, stages[2].Explanation)
+		}
+	}
+}
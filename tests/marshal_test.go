@@ -0,0 +1,163 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func marshalRoundTrip(t *testing.T, sizes []uint) {
+	for _, n := range sizes {
+		for _, shuffle := range [...]uint{0, 1, 2, 10, 13} {
+			cube := New(n)
+			cube.Shuffle(shuffle)
+
+			data, err := json.Marshal(cube)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var other Cube
+			if err := json.Unmarshal(data, &other); err != nil {
+				t.Fatal(err)
+			}
+			if !cube.Equal(other) {
+				t.Errorf("JSON round trip failed! n=%d %s", n, data)
+			}
+
+			text, err := cube.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			other = Cube{}
+			if err := other.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if !cube.Equal(other) {
+				t.Errorf("text round trip failed! n=%d %s", n, text)
+			}
+
+			var fl, otherFl Flat
+			fl.PaintCube(cube)
+			data, err = json.Marshal(fl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &otherFl); err != nil {
+				t.Fatal(err)
+			}
+			if !otherFl.Cube().Equal(cube) {
+				t.Errorf("flat JSON round trip failed! n=%d %s", n, data)
+			}
+
+			list := MoveList{Size: n, Moves: randomMoves(n, int(shuffle))}
+			data, err = json.Marshal(list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var otherList MoveList
+			if err := json.Unmarshal(data, &otherList); err != nil {
+				t.Fatal(err)
+			}
+			if otherList.Size != n || !slices.Equal(otherList.Moves, list.Moves) {
+				t.Errorf("move list JSON round trip failed! n=%d %s", n, data)
+			}
+		}
+	}
+}
+
+func TestMarshalOdd(t *testing.T) {
+	marshalRoundTrip(t, []uint{3, 5, 7, 9})
+}
+
+func TestMarshalEven(t *testing.T) {
+	marshalRoundTrip(t, []uint{2, 4, 6, 8})
+}
+
+func TestMarshalForms(t *testing.T) {
+	data, err := json.Marshal(New(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"size":2,"faces":{"U":"yyyy","R":"oooo","F":"gggg","D":"wwww","L":"rrrr","B":"bbbb"}}`
+	if string(data) != want {
+		t.Errorf("got %s, expected %s", data, want)
+	}
+
+	ms, err := ParseMoves(3, "R U' M2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = json.Marshal(MoveList{Size: 3, Moves: ms})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"size":3,"moves":"R U' M M"}`
+	if string(data) != want {
+		t.Errorf("got %s, expected %s", data, want)
+	}
+
+	data, err = json.Marshal(ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `["x 1 clockwise","z 1 counterclockwise","x 0 counterclockwise","x 0 counterclockwise"]`
+	if string(data) != want {
+		t.Errorf("got %s, expected %s", data, want)
+	}
+	var other []Move
+	if err := json.Unmarshal(data, &other); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(other, ms) {
+		t.Errorf("moves JSON round trip failed! %s", data)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"size":1,"faces":{"U":"y","R":"o","F":"g","D":"w","L":"r","B":"b"}}`,
+		`{"size":2,"faces":{"U":"yyy","R":"oooo","F":"gggg","D":"wwww","L":"rrrr","B":"bbbb"}}`,
+		`{"size":2,"faces":{"U":"yyyy","R":"oooo","F":"gggg","D":"wwww","L":"rrrr","B":"bbbx"}}`,
+		`{"size":1000000,"faces":{}}`,
+		`{"size":"two"}`,
+		`[1, 2]`,
+		`"UUUU"`,
+	} {
+		var cube Cube
+		if err := json.Unmarshal([]byte(data), &cube); err == nil {
+			t.Errorf("expected an error for cube %s", data)
+		}
+	}
+	for _, data := range []string{
+		`{"size":3,"moves":"R Q"}`,
+		`{"size":1,"moves":""}`,
+		`{"size":3,"moves":"4R"}`,
+		`{"moves":3}`,
+	} {
+		var list MoveList
+		if err := json.Unmarshal([]byte(data), &list); err == nil {
+			t.Errorf("expected an error for moves %s", data)
+		}
+	}
+	for _, data := range []string{`"x 1"`, `"w 1 clockwise"`, `"x one clockwise"`, `"x 1 sideways"`, `7`} {
+		var m Move
+		if err := json.Unmarshal([]byte(data), &m); err == nil {
+			t.Errorf("expected an error for move %s", data)
+		}
+	}
+	for _, data := range []string{`"r r\ny y"`, `[["y"]]`} {
+		var fl Flat
+		if err := json.Unmarshal([]byte(data), &fl); err == nil {
+			t.Errorf("expected an error for flat %s", data)
+		}
+	}
+	if _, err := json.Marshal(MoveList{Size: 3, Moves: []Move{{Axis: Xax, Idx: 2}}}); err == nil {
+		t.Errorf("expected an error for a move out of range")
+	}
+}
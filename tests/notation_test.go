@@ -0,0 +1,207 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func randomMoves(n uint, times int) []Move {
+	axes := [...]Axis{Xax, Yax, Zax}
+	dirs := [...]Direction{Counterclock, Clock}
+	var ms []Move
+	for len(ms) < times {
+		idx := rand.Intn(int(n)+1) - int(n)/2
+		if idx > int(n)/2 || (n%2 == 0 && idx == 0) {
+			continue
+		}
+		ms = append(ms, Move{Axis: axes[rand.Intn(len(axes))], Idx: idx, Direction: dirs[rand.Intn(len(dirs))]})
+	}
+	return ms
+}
+
+func TestParseMoves(t *testing.T) {
+	ms, err := ParseMoves(3, "R U R' U'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Move{
+		{Axis: Xax, Idx: 1, Direction: Clock},
+		{Axis: Zax, Idx: 1, Direction: Clock},
+		{Axis: Xax, Idx: 1, Direction: Counterclock},
+		{Axis: Zax, Idx: 1, Direction: Counterclock},
+	}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("got %v, expected %v", ms, expected)
+	}
+
+	ms, err = ParseMoves(3, "L2 D B'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Move{
+		{Axis: Xax, Idx: -1, Direction: Counterclock},
+		{Axis: Xax, Idx: -1, Direction: Counterclock},
+		{Axis: Zax, Idx: -1, Direction: Counterclock},
+		{Axis: Yax, Idx: -1, Direction: Clock},
+	}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("got %v, expected %v", ms, expected)
+	}
+}
+
+func TestParseRotations(t *testing.T) {
+	for _, n := range []uint{2, 3, 4, 5} {
+		for str, ax := range map[string]Axis{"x": Xax, "y": Zax, "z": Yax} {
+			ms, err := ParseMoves(n, str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cube := New(n)
+			cube.Shuffle(10)
+			if !cube.MoveAll(ms).Equal(cube.Rotate(ax, Clock)) {
+				t.Errorf("%s is not a rotation about %s! n=%d", str, ax, n)
+			}
+		}
+	}
+}
+
+func TestParseWide(t *testing.T) {
+	for _, tc := range []struct {
+		n        uint
+		str      string
+		expected string
+	}{
+		{3, "Rw", "R M'"},
+		{3, "r'", "R' M"},
+		{4, "3Rw", "R 2R 3R"},
+		{5, "3Fw2", "F2 2F2 3F2"},
+		{3, "x", "R M' L'"},
+		{5, "M", "2L 3L 4L"},
+		{5, "E2 S'", "2D2 3D2 4D2 2F' 3F' 4F'"},
+	} {
+		ms, err := ParseMoves(tc.n, tc.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := ParseMoves(tc.n, tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !New(tc.n).MoveAll(ms).Equal(New(tc.n).MoveAll(other)) {
+			t.Errorf("%q differs from %q! n=%d", tc.str, tc.expected, tc.n)
+		}
+	}
+}
+
+// A half turn and a prime may come in either order
+func TestParseHalfTurnPrime(t *testing.T) {
+	for _, tc := range []struct {
+		n   uint
+		str string
+	}{
+		{3, "R2'"},
+		{3, "R'2"},
+		{3, "R’2"},
+		{4, "2R'2"},
+		{5, "M'2"},
+	} {
+		ms, err := ParseMoves(tc.n, tc.str)
+		if err != nil {
+			t.Errorf("%q: %v", tc.str, err)
+			continue
+		}
+		if len(ms) == 0 || len(ms)%2 != 0 || ms[0].Direction != ms[len(ms)/2].Direction {
+			t.Errorf("%q is not a half turn: %v", tc.str, ms)
+		}
+		if !New(tc.n).MoveAll(ms).Equal(New(tc.n).MoveAll(ms[:len(ms)/2]).MoveAll(ms[:len(ms)/2])) {
+			t.Errorf("%q does not turn the same layers twice: %v", tc.str, ms)
+		}
+	}
+	ms, err := ParseMoves(3, "R'2 U2'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Move{
+		{Axis: Xax, Idx: 1, Direction: Counterclock},
+		{Axis: Xax, Idx: 1, Direction: Counterclock},
+		{Axis: Zax, Idx: 1, Direction: Counterclock},
+		{Axis: Zax, Idx: 1, Direction: Counterclock},
+	}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("got %v, expected %v", ms, expected)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		n   uint
+		str string
+		pos int
+	}{
+		{3, "R Q", 2},
+		{3, "R U 4R", 4},
+		{2, "R M", 2},
+		{3, "R3", 0},
+		{3, "R U 3", 4},
+		{3, "U 0R", 2},
+		{4, "3r", 0},
+		{3, "R (U)", 2},
+		{3, "R2'2", 0},
+		{3, "R'22", 0},
+	} {
+		_, err := ParseMoves(tc.n, tc.str)
+		var nerr *NotationError
+		if !errors.As(err, &nerr) {
+			t.Errorf("expected a NotationError for %q, got %v", tc.str, err)
+			continue
+		}
+		if nerr.Pos != tc.pos {
+			t.Errorf("%q: expected an error at %d, got %d (%v)", tc.str, tc.pos, nerr.Pos, err)
+		}
+	}
+}
+
+func TestFormatMoves(t *testing.T) {
+	for _, tc := range []struct {
+		n   uint
+		str string
+	}{
+		{3, "R U R' U'"},
+		{3, "R2 U2 F B' L D2"},
+		{3, "M E' S2 x y' z2"},
+		{4, "Rw U 3Fw' 2L2"},
+		{5, "R U R' U2 Rw 3Fw' M E S x y z"},
+	} {
+		ms, err := ParseMoves(tc.n, tc.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str := FormatMoves(tc.n, ms); str != tc.str {
+			t.Errorf("got %q, expected %q", str, tc.str)
+		}
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	for _, n := range []uint{2, 3, 4, 5, 6, 7} {
+		for range 10 {
+			ms := randomMoves(n, 30)
+			str := FormatMoves(n, ms)
+			other, err := ParseMoves(n, str)
+			if err != nil {
+				t.Fatalf("%q: %v", str, err)
+			}
+			if !New(n).MoveAll(ms).Equal(New(n).MoveAll(other)) {
+				t.Errorf("formatting and parsing failed! n=%d %q", n, str)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestCompile(t *testing.T) {
+	for n := uint(2); n <= 6; n++ {
+		cube := New(n)
+		cube.Shuffle(30)
+		ms := randomMoves(n, 40)
+		p := Compile(n, ms)
+		if !cube.Permute(p).Equal(cube.MoveAll(ms)) {
+			t.Errorf("compiled moves differ from the moves on a cube of size %d", n)
+		}
+		if !cube.Permute(p).Permute(p.Inverse()).Equal(cube) {
+			t.Errorf("inverse does not undo the permutation on a cube of size %d", n)
+		}
+		more := randomMoves(n, 10)
+		if !cube.Permute(p.Then(Compile(n, more))).Equal(cube.MoveAll(ms).MoveAll(more)) {
+			t.Errorf("composed permutation differs from the moves on a cube of size %d", n)
+		}
+	}
+}
+
+func TestCompileIdentity(t *testing.T) {
+	sexy, err := ParseAlgorithm(3, "[R, U]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3)
+	cube.Shuffle(30)
+	if !cube.Permute(Compile(3, sexy.Repeat(6))).Equal(cube) {
+		t.Errorf("(R U R' U')6 is not the identity")
+	}
+	if cube.Permute(Compile(3, sexy.Repeat(3))).Equal(cube) {
+		t.Errorf("(R U R' U')3 is the identity")
+	}
+	if !cube.Permute(Compile(3, nil)).Equal(cube) {
+		t.Errorf("no moves is not the identity")
+	}
+}
+
+func BenchmarkPermute(b *testing.B) {
+	ms := randomMoves(5, 100)
+	p := Compile(5, ms)
+	cube := New(5)
+	for b.Loop() {
+		cube = cube.Permute(p)
+	}
+}
+
+func BenchmarkMoveAll(b *testing.B) {
+	ms := randomMoves(5, 100)
+	cube := New(5)
+	for b.Loop() {
+		cube = cube.MoveAll(ms)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+// Length of a path in a metric, counting two equal moves in a row as a
+// single half turn in HTM
+func pathLength(ms []Move, metric Metric) int {
+	if metric == QTM {
+		return len(ms)
+	}
+	ret := 0
+	for i := 0; i < len(ms); i++ {
+		if i+1 < len(ms) && ms[i] == ms[i+1] {
+			i++
+		}
+		ret++
+	}
+	return ret
+}
+
+// Checks that the path takes start to end, up to a rotation of the cube
+func reaches(start Cube, path []Move, end Cube) bool {
+	got := start.MoveAll(path)
+	for _, rotated := range end.GetAllRotations() {
+		if rotated.Equal(got) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPocketSolver(t *testing.T) {
+	for _, tc := range []struct {
+		metric Metric
+		max    int
+	}{
+		{HTM, 11},
+		{QTM, 14},
+	} {
+		solver := PocketSolver{Metric: tc.metric}
+		for range 20 {
+			cube := New(2)
+			cube.Shuffle(30)
+			path := solver.GetPath(cube, New(2))
+			if path == nil {
+				t.Fatalf("no path found!\n%s", cube)
+			}
+			if !cube.MoveAll(path).IsSolved() {
+				t.Errorf("path does not solve the cube! %v\n%s", path, cube)
+			}
+			if l := pathLength(path, tc.metric); l > tc.max {
+				t.Errorf("path of length %d, expected at most %d", l, tc.max)
+			}
+		}
+	}
+}
+
+func TestPocketSolverOptimal(t *testing.T) {
+	for _, metric := range []Metric{HTM, QTM} {
+		solver := PocketSolver{Metric: metric}
+		for _, str := range []string{"", "R", "U2", "R U", "F R' U2", "R U R' U'", "R U2 F' R2 U'"} {
+			ms, err := ParseMoves(2, str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scramble := New(2).MoveAll(ms)
+			path := solver.GetPath(scramble, New(2))
+			if path == nil {
+				t.Fatalf("no path found for %q", str)
+			}
+			if !scramble.MoveAll(path).IsSolved() {
+				t.Errorf("path does not solve %q", str)
+			}
+			if l, max := pathLength(path, metric), pathLength(ms, metric); l > max {
+				t.Errorf("%q solved in %d moves, expected at most %d", str, l, max)
+			}
+		}
+	}
+}
+
+func TestPocketSolverEnd(t *testing.T) {
+	solver := PocketSolver{}
+	for range 10 {
+		start, end := New(2), New(2)
+		start.Shuffle(20)
+		end.Shuffle(20)
+		end = end.Rotate(Xax, Counterclock)
+		path := solver.GetPath(start, end)
+		if path == nil {
+			t.Fatal("no path found!")
+		}
+		if !reaches(start, path, end) {
+			t.Errorf("path does not reach the end cube! %v", path)
+		}
+	}
+}
+
+func TestPocketSolverInvalid(t *testing.T) {
+	solver := PocketSolver{}
+	if path := solver.GetPath(New(3), New(3)); path != nil {
+		t.Errorf("expected no path for a 3x3x3 cube, got %v", path)
+	}
+
+	// Twist the corner at URF
+	var fl Flat
+	fl.PaintCube(New(2))
+	fl[1][3], fl[2][3], fl[2][4] = fl[2][4], fl[1][3], fl[2][3]
+	twisted := fl.Cube()
+	if path := solver.GetPath(twisted, New(2)); path != nil {
+		t.Errorf("expected no path for a twisted corner, got %v", path)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestReductionSolver(t *testing.T) {
+	solver := ReductionSolver{}
+	for n := uint(2); n <= 7; n++ {
+		for range 3 {
+			cube := New(n)
+			cube.Shuffle(100)
+			path := solver.GetPath(cube, New(n))
+			if path == nil {
+				t.Fatalf("no path found!\n%s", cube)
+			}
+			if !cube.MoveAll(path).IsSolved() {
+				t.Errorf("path does not solve the cube of size %d! %v\n%s", n, path, cube)
+			}
+		}
+	}
+}
+
+func TestReductionSolverParity(t *testing.T) {
+	solver := ReductionSolver{}
+	for _, tc := range []struct {
+		n     uint
+		moves string
+	}{
+		{4, "2R"},                     // OLL parity
+		{4, "2R2 U2 2R2 Uw2 2R2 Uw2"}, // PLL parity
+		{4, "R U R' U' R' F R2 U' R' U' R U R' F'"}, // corners swapped
+		{5, "2R"},
+		{6, "2R 3R'"},
+		{6, "R U R' U' R' F R2 U' R' U' R U R' F'"},
+	} {
+		ms, err := ParseMoves(tc.n, tc.moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cube := New(tc.n).MoveAll(ms)
+		path := solver.GetPath(cube, New(tc.n))
+		if path == nil {
+			t.Fatalf("no path found for %s!", tc.moves)
+		}
+		if !cube.MoveAll(path).IsSolved() {
+			t.Errorf("path does not solve %s on the cube of size %d! %v", tc.moves, tc.n, path)
+		}
+	}
+}
+
+func TestReductionSolverEnd(t *testing.T) {
+	solver := ReductionSolver{}
+	for _, n := range []uint{4, 5} {
+		start, end := New(n), New(n)
+		start.Shuffle(50)
+		end.Shuffle(50)
+		end = end.Rotate(Yax, Clock)
+		path := solver.GetPath(start, end)
+		if path == nil {
+			t.Fatal("no path found!")
+		}
+		if !reaches(start, path, end) {
+			t.Errorf("path does not reach the end cube of size %d! %v", n, path)
+		}
+	}
+}
+
+func TestReductionSolverInvalid(t *testing.T) {
+	solver := ReductionSolver{}
+	if path := solver.GetPath(New(4), New(5)); path != nil {
+		t.Errorf("expected no path between cubes of different sizes, got %v", path)
+	}
+
+	// Turn the edge piece at UF around
+	var fl Flat
+	fl.PaintCube(New(4))
+	fl[3][5], fl[4][5] = fl[4][5], fl[3][5]
+	cube := fl.Cube()
+	if path := solver.GetPath(cube, New(4)); path != nil {
+		t.Errorf("expected no path for a flipped edge piece, got %v", path)
+	}
+}
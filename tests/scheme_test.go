@@ -0,0 +1,156 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestSchemeNew(t *testing.T) {
+	for n := uint(2); n <= 5; n++ {
+		if !Western().New(n).Equal(New(n)) {
+			t.Errorf("the Western scheme does not color a cube of size %d as New", n)
+		}
+		cube := Japanese().New(n)
+		if err := cube.Validate(); err == nil {
+			t.Errorf("Japanese cube of size %d validates as a Western cube", n)
+		}
+		if err := Japanese().Validate(cube); err != nil {
+			t.Errorf("Japanese cube of size %d does not validate with its scheme: %v", n, err)
+		}
+		if !cube.IsSolved() {
+			t.Errorf("Japanese cube of size %d is not solved", n)
+		}
+		if n%2 == 1 {
+			if cube.IsCanonical() || !Japanese().IsCanonical(cube) {
+				t.Errorf("Japanese cube of size %d is canonical in the wrong scheme", n)
+			}
+			if !Western().IsCanonical(New(n)) {
+				t.Errorf("cube of size %d is not canonical in the Western scheme", n)
+			}
+		}
+	}
+
+	var fl Flat
+	fl.PaintCube(Japanese().New(3))
+	for _, tc := range []struct {
+		r, c int
+		want string
+	}{
+		{1, 4, "y"},  // up
+		{4, 1, "r"},  // left
+		{4, 4, "w"},  // front
+		{4, 7, "o"},  // right
+		{4, 10, "b"}, // back
+		{7, 4, "g"},  // down
+	} {
+		if got := fl[tc.r][tc.c]; got != tc.want {
+			t.Errorf("sticker at %d, %d is %s, expected %s", tc.r, tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestSchemeCustom(t *testing.T) {
+	scheme := ColorScheme{Faces: [6]Color{Red, Yellow, Blue, Orange, White, Green}}
+	cube := scheme.New(3)
+	if !scheme.IsCanonical(cube) || cube.IsCanonical() {
+		t.Errorf("custom cube is canonical in the wrong scheme")
+	}
+	if s, err := scheme.Facelets(cube); err != nil || !strings.HasPrefix(s, "UUUUUUUUU") {
+		t.Errorf("facelets of the custom cube are %s, %v", s, err)
+	}
+	// Yellow opposite orange cannot be reached by rotating a Western cube
+	if err := cube.Validate(); err == nil {
+		t.Errorf("expected the custom cube not to validate as a Western cube")
+	}
+	if err := scheme.Validate(cube.Move(Move{Axis: Xax, Idx: 1, Direction: Clock})); err != nil {
+		t.Errorf("custom cube does not validate with its scheme: %v", err)
+	}
+
+	cube = New(3)
+	scheme.Reset(&cube)
+	if !scheme.IsCanonical(cube) {
+		t.Errorf("Reset did not color the cube with the scheme")
+	}
+}
+
+func TestSchemeInvalid(t *testing.T) {
+	for _, scheme := range []ColorScheme{
+		{},
+		{Faces: [6]Color{Yellow, Yellow, Green, White, Red, Blue}},
+		{Faces: [6]Color{Yellow, Orange, -Green, White, Red, Blue}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for scheme %v", scheme.Faces)
+				}
+			}()
+			scheme.New(3)
+		}()
+	}
+}
+
+func TestText(t *testing.T) {
+	cube := New(3)
+	if got := cube.Text(TextOptions{Mode: NoColor}); got != cube.String() {
+		t.Errorf("text without colors differs from String:\n%s", got)
+	}
+	for _, tc := range []struct {
+		mode   ColorMode
+		orange string
+	}{
+		{TrueColor, "\033[38;2;255;88;0mo\033[0m"},
+		{ANSI256, "\033[38;5;202mo\033[0m"},
+		{ANSI16, "\033[91mo\033[0m"},
+	} {
+		text := cube.Text(TextOptions{Mode: tc.mode})
+		if !strings.Contains(text, tc.orange) {
+			t.Errorf("expected orange as %q in mode %d:\n%s", tc.orange, tc.mode, text)
+		}
+		var fl Flat
+		if err := fl.FromString(text); err != nil {
+			t.Fatalf("reading text in mode %d: %v", tc.mode, err)
+		}
+		if !fl.Cube().Equal(cube) {
+			t.Errorf("text in mode %d does not read back as the cube", tc.mode)
+		}
+	}
+
+	scheme := Japanese()
+	scheme.Palette[White].B = 0xf0
+	text := cube.Text(TextOptions{Scheme: scheme})
+	if !strings.Contains(text, "\033[38;2;255;255;240mw") {
+		t.Errorf("expected the white of the scheme:\n%s", text)
+	}
+}
+
+// A scheme that only sets the faces is displayed in the standard colors
+func TestSchemeDefaultPalette(t *testing.T) {
+	scheme := ColorScheme{Faces: [6]Color{Red, Yellow, Blue, Orange, White, Green}}
+	cube := scheme.New(3)
+	if text := cube.Text(TextOptions{Scheme: scheme}); !strings.Contains(text, "\033[38;2;255;88;0mo\033[0m") {
+		t.Errorf("expected the standard orange:\n%s", text)
+	}
+	var buf bytes.Buffer
+	if err := cube.WriteSVG(&buf, SVGOptions{Scheme: scheme}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `fill="#ff5800"`) || strings.Contains(buf.String(), `fill="#000000"`) {
+		t.Errorf("expected the standard colors:\n%s", buf.String())
+	}
+	img, err := cube.Image(ImageOptions{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Palette[1+int(Orange)]; got != (color.RGBA{0xff, 0x58, 0x00, 0xff}) {
+		t.Errorf("orange drawn as %v", got)
+	}
+}
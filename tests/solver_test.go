@@ -0,0 +1,121 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/dfava/cube"
+)
+
+var (
+	_ SearchSolver = PocketSolver{}
+	_ SearchSolver = KociembaSolver{}
+	_ SearchSolver = ReductionSolver{}
+	_ SearchSolver = BidirectionalSolver{}
+	_ SearchSolver = LayerSolver{}
+)
+
+func TestSearch(t *testing.T) {
+	for _, tc := range []struct {
+		solver SearchSolver
+		n      uint
+	}{
+		{PocketSolver{}, 2},
+		{KociembaSolver{}, 3},
+		{ReductionSolver{}, 4},
+		{LayerSolver{}, 3},
+	} {
+		cube := New(tc.n)
+		cube.Shuffle(50)
+		var reports []Progress
+		path, err := tc.solver.Search(context.Background(), cube, New(tc.n), Options{
+			Progress: func(p Progress) { reports = append(reports, p) },
+		})
+		if err != nil {
+			t.Fatalf("%T: %v", tc.solver, err)
+		}
+		if !cube.MoveAll(path).IsSolved() {
+			t.Errorf("%T: path does not solve the cube! %v", tc.solver, path)
+		}
+		if len(reports) == 0 {
+			t.Errorf("%T: no progress reported", tc.solver)
+		}
+	}
+}
+
+func TestSearchProgress(t *testing.T) {
+	cube := New(3)
+	cube.Shuffle(50)
+	var reports []Progress
+	_, err := KociembaSolver{}.Search(context.Background(), cube, New(3), Options{
+		Progress: func(p Progress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Depth != reports[i-1].Depth+1 || reports[i].Nodes < reports[i-1].Nodes {
+			t.Errorf("progress went from %+v to %+v", reports[i-1], reports[i])
+		}
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tc := range []struct {
+		solver SearchSolver
+		n      uint
+	}{
+		{PocketSolver{}, 2},
+		{KociembaSolver{MaxLength: 16}, 3},
+		{ReductionSolver{}, 5},
+		{LayerSolver{}, 3},
+	} {
+		cube := New(tc.n)
+		cube.Shuffle(50)
+		if _, err := tc.solver.Search(ctx, cube, New(tc.n), Options{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("%T: expected %v, got %v", tc.solver, context.Canceled, err)
+		}
+	}
+}
+
+func TestSearchTimeout(t *testing.T) {
+	solver := KociembaSolver{}
+	if err := solver.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	cube := New(3)
+	cube.Shuffle(50)
+	begin := time.Now()
+	_, err := solver.Search(context.Background(), cube, New(3), Options{MaxDepth: 16, Timeout: 10 * time.Millisecond})
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("search took %s despite a timeout", elapsed)
+	}
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrNoPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	ms, err := ParseMoves(2, "R U F")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := New(2).MoveAll(ms)
+	if _, err := (PocketSolver{}).Search(context.Background(), cube, New(2), Options{MaxDepth: 2}); err != ErrNoPath {
+		t.Errorf("expected %v, got %v", ErrNoPath, err)
+	}
+	if _, err := (KociembaSolver{}).Search(context.Background(), New(3), New(3), Options{Metric: QTM}); err != ErrMetric {
+		t.Errorf("expected %v, got %v", ErrMetric, err)
+	}
+	if _, err := (KociembaSolver{}).Search(context.Background(), New(2), New(2), Options{}); err != ErrNoPath {
+		t.Errorf("expected %v, got %v", ErrNoPath, err)
+	}
+}
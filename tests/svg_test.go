@@ -0,0 +1,137 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+// Parses an SVG image, returning its root element and the attributes of
+// the elements in it by name
+func parseSVG(t *testing.T, data []byte) (xml.StartElement, map[string][]map[string]string, []string) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root xml.StartElement
+	elems := make(map[string][]map[string]string)
+	var texts []string
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, data)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				root = tok
+			}
+			depth++
+			attrs := make(map[string]string)
+			for _, a := range tok.Attr {
+				attrs[a.Name.Local] = a.Value
+			}
+			elems[tok.Name.Local] = append(elems[tok.Name.Local], attrs)
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+	}
+	return root, elems, texts
+}
+
+func TestSVG(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		cube := New(n)
+		cube.Shuffle(20)
+		var buf bytes.Buffer
+		if err := cube.WriteSVG(&buf, SVGOptions{StickerSize: 10, Gap: 1}); err != nil {
+			t.Fatal(err)
+		}
+		root, elems, _ := parseSVG(t, buf.Bytes())
+		if root.Name.Local != "svg" || root.Name.Space != "http://www.w3.org/2000/svg" {
+			t.Errorf("root element is %v", root.Name)
+		}
+		if got := len(elems["rect"]); got != 6*int(n*n) {
+			t.Errorf("%d stickers drawn for a cube of size %d", got, n)
+		}
+		counts := make(map[string]int)
+		for _, rect := range elems["rect"] {
+			counts[rect["fill"]]++
+		}
+		palette := Western().Palette
+		for _, rgb := range palette[Green:] {
+			fill := fmt.Sprintf("#%02x%02x%02x", rgb.R, rgb.G, rgb.B)
+			if counts[fill] != int(n*n) {
+				t.Errorf("%d stickers of color %s on a cube of size %d", counts[fill], fill, n)
+			}
+		}
+	}
+}
+
+func TestSVGOptions(t *testing.T) {
+	var fl Flat
+	fl.PaintCube(New(3))
+	var buf bytes.Buffer
+	scheme := Western()
+	scheme.Palette[Yellow] = color.RGBA{0xff, 0xd7, 0x00, 0xff}
+	err := fl.WriteSVG(&buf, SVGOptions{
+		StickerSize: 20,
+		Gap:         2,
+		Scheme:      scheme,
+		Highlight:   map[[2]int]bool{{0, 3}: true, {4, 4}: true},
+		Labels:      map[[2]int]string{{4, 4}: "F<&>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, elems, texts := parseSVG(t, buf.Bytes())
+	for _, attr := range root.Attr {
+		// 12 stickers of 20, 11 gaps of 2 between them and 3 more between
+		// sides, and a margin of 4 on each side
+		if attr.Name.Local == "width" && attr.Value != "276" {
+			t.Errorf("width %s, expected 276", attr.Value)
+		}
+	}
+	gold, outlines := 0, 0
+	for _, rect := range elems["rect"] {
+		if rect["fill"] == "#ffd700" {
+			gold++
+		}
+		if rect["fill"] == "none" {
+			outlines++
+		}
+	}
+	if gold != 9 {
+		t.Errorf("%d gold stickers, expected 9", gold)
+	}
+	if outlines != 2 {
+		t.Errorf("%d highlighted stickers, expected 2", outlines)
+	}
+	if len(texts) != 1 || texts[0] != "F<&>" {
+		t.Errorf("labels %q, expected F<&>", texts)
+	}
+}
+
+func TestSVGBadColor(t *testing.T) {
+	var fl Flat
+	fl.PaintCube(New(2))
+	fl[0][2] = "x"
+	if err := fl.WriteSVG(io.Discard, SVGOptions{}); err == nil {
+		t.Errorf("expected an error for an unknown color")
+	}
+}
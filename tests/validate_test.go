@@ -0,0 +1,82 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/dfava/cube"
+)
+
+func TestValidate(t *testing.T) {
+	for n := uint(2); n <= 7; n++ {
+		cube := New(n)
+		cube.Shuffle(100)
+		cube = cube.Move(Move{Axis: Yax, Idx: 1, Direction: Clock}).Rotate(Xax, Clock)
+		if err := cube.Validate(); err != nil {
+			t.Errorf("valid cube of size %d rejected: %v", n, err)
+		}
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	// Stickers of a flat, by row and column
+	type sticker [2]int
+	swap := func(a, b sticker) func(Flat) {
+		return func(fl Flat) { fl[a[0]][a[1]], fl[b[0]][b[1]] = fl[b[0]][b[1]], fl[a[0]][a[1]] }
+	}
+	set := func(s sticker, color string) func(Flat) {
+		return func(fl Flat) { fl[s[0]][s[1]] = color }
+	}
+	for _, tc := range []struct {
+		name    string
+		n       uint
+		edits   []func(Flat)
+		problem Problem
+	}{
+		{"missing sticker", 3, []func(Flat){set(sticker{0, 3}, " ")}, BadSticker},
+		{"extra yellow", 3, []func(Flat){set(sticker{3, 3}, "y")}, ColorCount},
+		{"U and D centers swapped", 3, []func(Flat){swap(sticker{1, 4}, sticker{7, 4})}, BadCenters},
+		{"mirrored corner", 3, []func(Flat){swap(sticker{3, 5}, sticker{3, 6})}, UnknownPiece},
+		{"duplicate edges", 3, []func(Flat){
+			set(sticker{8, 4}, "y"), set(sticker{5, 10}, "g"), // DB is UF
+			set(sticker{0, 4}, "w"), set(sticker{3, 10}, "b"), // UB is DB
+			set(sticker{4, 5}, "b"), set(sticker{4, 6}, "o"), // FR is BR
+		}, DuplicatePiece},
+		{"twisted corner", 3, []func(Flat){swap(sticker{2, 5}, sticker{3, 5}), swap(sticker{2, 5}, sticker{3, 6})}, TwistedCorner},
+		{"twisted corner", 2, []func(Flat){swap(sticker{1, 3}, sticker{2, 3}), swap(sticker{1, 3}, sticker{2, 4})}, TwistedCorner},
+		{"flipped edge", 3, []func(Flat){swap(sticker{2, 4}, sticker{3, 4})}, FlippedEdge},
+		{"two edges swapped", 3, []func(Flat){swap(sticker{3, 4}, sticker{3, 10})}, Parity},
+		{"flipped edge piece, the same as its twin", 4, []func(Flat){swap(sticker{3, 5}, sticker{4, 5})}, DuplicatePiece},
+		{"centers of two groups swapped", 5, []func(Flat){swap(sticker{1, 6}, sticker{6, 7})}, ColorCount},
+	} {
+		var fl Flat
+		fl.PaintCube(New(tc.n))
+		for _, edit := range tc.edits {
+			edit(fl)
+		}
+		err := fl.Cube().Validate()
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s on a cube of size %d: expected a validation error, got %v", tc.name, tc.n, err)
+			continue
+		}
+		if verr.Problem != tc.problem {
+			t.Errorf("%s on a cube of size %d: expected %s, got %v", tc.name, tc.n, tc.problem, err)
+		}
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	var fl Flat
+	fl.PaintCube(New(3))
+	fl[3][5], fl[3][6] = fl[3][6], fl[3][5] // the corner at UFR
+	err := fl.Cube().Validate()
+	want := &ValidationError{Problem: UnknownPiece, Position: [3]int{1, 1, 1}}
+	if verr, ok := err.(*ValidationError); !ok || *verr != *want {
+		t.Errorf("expected %v, got %v", want, err)
+	}
+}
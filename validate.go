@@ -0,0 +1,281 @@
+// Copyright 2020 Daniel S. Fava. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cube
+
+import "fmt"
+
+// Problem is a reason why a cube cannot be reached from a solved cube
+type Problem int
+
+const (
+	// BadSticker: a visible sticker has no color, or a hidden one has
+	BadSticker Problem = iota
+	// ColorCount: a color does not cover n*n stickers, or a group of
+	// centers that can take each other's place does not have four of
+	// each color
+	ColorCount
+	// BadCenters: the centers of a cube of odd size are not arranged as
+	// on a solved cube
+	BadCenters
+	// UnknownPiece: a piece has colors that no piece of a solved cube has,
+	// or has them in mirror image
+	UnknownPiece
+	// DuplicatePiece: two pieces have the same colors
+	DuplicatePiece
+	// TwistedCorner: the twists of the corners do not add up
+	TwistedCorner
+	// FlippedEdge: the flips of the edges do not add up
+	FlippedEdge
+	// Parity: the corners and the edges of a cube of odd size are not
+	// permuted with the same parity
+	Parity
+)
+
+func (p Problem) String() string {
+	switch p {
+	case BadSticker:
+		return "bad sticker"
+	case ColorCount:
+		return "wrong color count"
+	case BadCenters:
+		return "centers out of place"
+	case UnknownPiece:
+		return "unknown piece"
+	case DuplicatePiece:
+		return "duplicate piece"
+	case TwistedCorner:
+		return "twisted corner"
+	case FlippedEdge:
+		return "flipped edge"
+	case Parity:
+		return "parity"
+	}
+	return fmt.Sprintf("Problem(%d)", int(p))
+}
+
+// ValidationError tells why a cube cannot be reached from a solved cube
+type ValidationError struct {
+	Problem Problem
+	// Position is where the problem is, for bad stickers and for unknown
+	// and duplicate pieces
+	Position [3]int
+	// Color is the color counted wrong, for wrong color counts
+	Color Color
+}
+
+func (e *ValidationError) Error() string {
+	switch e.Problem {
+	case BadSticker, UnknownPiece, DuplicatePiece:
+		return fmt.Sprintf("cube: %s at %v", e.Problem, e.Position)
+	case ColorCount:
+		return fmt.Sprintf("cube: %s for %s", e.Problem, e.Color)
+	}
+	return fmt.Sprintf("cube: %s", e.Problem)
+}
+
+// Validate tells whether a cube can be reached from a solved cube by
+// moves and rotations.  It returns nil if it can, or a *ValidationError
+// for the first problem found otherwise.
+//
+// Cubes made by moves always can.  Validate is meant for cubes read from
+// a Flat, to reject impossible ones before solving them.  The cube must be
+// in the Western color scheme, see ColorScheme.Validate for others.
+func (cube Cube) Validate() error {
+	return Western().Validate(cube)
+}
+
+// Validate tells whether a cube can be reached from a cube solved with the
+// scheme, as Cube.Validate does
+func (s ColorScheme) Validate(cube Cube) error {
+	n := cube.n
+	h := int(n / 2)
+	var count [Blue + 1]int
+	for _, cbi := range cube.cubis {
+		for ax, x := range cbi.pv {
+			c := cbi.cv[ax]
+			if x != h && x != -h {
+				if c != zero {
+					return &ValidationError{Problem: BadSticker, Position: cbi.pv}
+				}
+				continue
+			}
+			if c.Abs() < Green || c.Abs() > Blue || (c < 0) != (x < 0) {
+				return &ValidationError{Problem: BadSticker, Position: cbi.pv}
+			}
+			count[c.Abs()]++
+		}
+	}
+	for c := Green; c <= Blue; c++ {
+		if count[c] != int(n*n) {
+			return &ValidationError{Problem: ColorCount, Color: c}
+		}
+	}
+
+	// The faces, given by the centers or by the corner at DBL
+	var f faceColors
+	if n%2 == 1 {
+		f = centerFaces(cube)
+		if !s.solvedFaces()[f] {
+			return &ValidationError{Problem: BadCenters}
+		}
+	} else if g, ok := cornerFaces(cube); ok && s.solvedFaces()[g] {
+		f = g
+	} else {
+		return &ValidationError{Problem: UnknownPiece, Position: vec{-h, -h, -h}}
+	}
+
+	corners, err := cube.validateCorners(f)
+	if err != nil {
+		return err
+	}
+	twist := 0
+	for _, o := range corners.o {
+		twist += int(o)
+	}
+	if twist%3 != 0 {
+		return &ValidationError{Problem: TwistedCorner}
+	}
+	if n%2 == 1 {
+		edges, err := cube.validateEdges(f)
+		if err != nil {
+			return err
+		}
+		flip := 0
+		for _, o := range edges.o {
+			flip += int(o)
+		}
+		if flip%2 != 0 {
+			return &ValidationError{Problem: FlippedEdge}
+		}
+		if permParity(corners.p[:]) != permParity(edges.p[:]) {
+			return &ValidationError{Problem: Parity}
+		}
+	}
+	if n >= 4 {
+		return cube.validateOrbits(f)
+	}
+	return nil
+}
+
+func (cube Cube) validateCorners(f faceColors) (cornerCubies, error) {
+	var ret cornerCubies
+	h := int(cube.n / 2)
+	var seen [nCorners]bool
+	for slot, cs := range cornerSlots {
+		pos := vec{cs.pos[0] * h, cs.pos[1] * h, cs.pos[2] * h}
+		cbi := cube.cubis[cubiIndex(cube.n, pos)]
+		var colors [3]Color
+		for k, ax := range cs.axes {
+			colors[k] = cbi.cv[ax].Abs()
+		}
+		found := false
+		for piece := range nCorners {
+			home := f.corner(piece)
+			for twist := range 3 {
+				if colors[twist] == home[0] && colors[(twist+1)%3] == home[1] && colors[(twist+2)%3] == home[2] {
+					if seen[piece] {
+						return ret, &ValidationError{Problem: DuplicatePiece, Position: pos}
+					}
+					seen[piece] = true
+					ret.p[slot] = uint8(piece)
+					ret.o[slot] = uint8(twist)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return ret, &ValidationError{Problem: UnknownPiece, Position: pos}
+		}
+	}
+	return ret, nil
+}
+
+// Reads the edges in the middle of the edges of a cube of odd size
+func (cube Cube) validateEdges(f faceColors) (edgeCubies, error) {
+	var ret edgeCubies
+	h := int(cube.n / 2)
+	var seen [nEdges]bool
+	for slot, es := range edgeSlots {
+		pos := vec{es.pos[0] * h, es.pos[1] * h, es.pos[2] * h}
+		cbi := cube.cubis[cubiIndex(cube.n, pos)]
+		var colors [2]Color
+		for k, ax := range es.axes {
+			colors[k] = cbi.cv[ax].Abs()
+		}
+		found := false
+		for piece := range nEdges {
+			home := f.edge(piece)
+			for flip := range 2 {
+				if colors[flip] == home[0] && colors[1-flip] == home[1] {
+					if seen[piece] {
+						return ret, &ValidationError{Problem: DuplicatePiece, Position: pos}
+					}
+					seen[piece] = true
+					ret.p[slot] = uint8(piece)
+					ret.o[slot] = uint8(flip)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return ret, &ValidationError{Problem: UnknownPiece, Position: pos}
+		}
+	}
+	return ret, nil
+}
+
+// Checks the edge pieces that are not in the middle of their edge, which
+// are told apart by their mirror image, and the centers that are not in
+// the middle of their face, which only need the right number of each
+// color
+func (cube Cube) validateOrbits(f faceColors) error {
+	h := int(cube.n / 2)
+	t := getReductionTables(cube.n)
+	rots := cubeRotations()
+	solved := func(p vec) cVec {
+		var ret cVec
+		for ax, x := range p {
+			if x == h {
+				ret[ax] = f[ax][1]
+			} else if x == -h {
+				ret[ax] = -f[ax][0]
+			}
+		}
+		return ret
+	}
+	for _, o := range t.orbits {
+		if !o.wing {
+			var count [Blue + 1]int
+			for _, i := range o.positions {
+				count[sticker(cube.cubis[cubiIndex(cube.n, t.positions[i])].cv)]++
+			}
+			for c := Green; c <= Blue; c++ {
+				if count[c] != len(o.positions)/6 {
+					return &ValidationError{Problem: ColorCount, Color: c}
+				}
+			}
+			continue
+		}
+		seen := make([]bool, len(o.positions))
+		for p, i := range o.positions {
+			pos := t.positions[i]
+			cv := cube.cubis[cubiIndex(cube.n, pos)].cv
+			found := -1
+			for q, j := range o.positions {
+				if rots[o.rotTo[p][q]].mult(cubi{cv: cv}).cv == solved(t.positions[j]) {
+					found = q
+				}
+			}
+			if found < 0 {
+				return &ValidationError{Problem: UnknownPiece, Position: pos}
+			}
+			if seen[found] {
+				return &ValidationError{Problem: DuplicatePiece, Position: pos}
+			}
+			seen[found] = true
+		}
+	}
+	return nil
+}